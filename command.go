@@ -0,0 +1,287 @@
+package yacl
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type commandInfo struct {
+	name  string
+	usage string
+}
+
+// NewCommand creates a new subcommand of the given parent. All parse-results of the subcommand will be reflected
+// in the given destination. The options of the parent are inherited and can be extended by the given ones.
+// Flags which are unknown to the subcommand are passed to its parent (global flags).
+func NewCommand[T any](parent *Config, name, usage string, destination *T, opts ...Option) *Config {
+	c := &Config{
+		options: parent.options,
+		dest:    destination,
+		name:    name,
+		usage:   usage,
+		parent:  parent,
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(&c.options)
+	}
+
+	parent.commands = append(parent.commands, c)
+	return c
+}
+
+// Command returns the path of the selected (sub)command of the last parsed arguments (e.g. ["remote", "add"]).
+// It is empty if no subcommand was selected.
+func (c *Config) Command() []string {
+	var result []string
+	for _, cmd := range c.chain[min(1, len(c.chain)):] {
+		result = append(result, cmd.name)
+	}
+	return result
+}
+
+// commandList returns all subcommands: the fields of the destination struct which are tagged as command and
+// all subcommands which are registered by NewCommand.
+func (c *Config) commandList() []*Config {
+	var result []*Config
+
+	v := reflect.ValueOf(c.dest)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := field.Tag.Get(c.options.commandTag)
+			if name == "" || !field.IsExported() {
+				continue
+			}
+
+			key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if key == "" || key == "-" {
+				continue
+			}
+
+			cmd := &Config{
+				options: c.options,
+				name:    name,
+				usage:   c.getUsage(t, field),
+				parent:  c,
+				prefix:  []string{key},
+			}
+
+			fv := v.Field(i)
+			switch {
+			case field.Type.Kind() == reflect.Struct:
+				cmd.dest = fv.Addr().Interface()
+			case field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct:
+				if fv.IsNil() {
+					// the struct will be only set if the command is selected
+					nv := reflect.New(field.Type.Elem())
+					cmd.dest = nv.Interface()
+					cmd.activate = func() {
+						fv.Set(nv)
+					}
+				} else {
+					cmd.dest = fv.Interface()
+				}
+			default:
+				continue
+			}
+
+			result = append(result, cmd)
+		}
+	}
+
+	return append(result, c.commands...)
+}
+
+func (c *Config) findCommand(name string) *Config {
+	for _, cmd := range c.commandList() {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// parseCommandLine parses the given arguments: the first positional argument which matches the name of a
+// subcommand selects it. All following arguments are assigned to the subcommand. Flags which are unknown to the
+// subcommand are assigned to the nearest parent which knows them.
+func (c *Config) parseCommandLine(args []string) error {
+	// the chain of a previous (maybe failed) call is no longer valid
+	c.chain = nil
+	chain, routed := c.route(args)
+
+	for i, cmd := range chain {
+		sub := make([]string, len(routed[i]))
+		for j, argIndex := range routed[i] {
+			sub[j] = args[argIndex]
+		}
+
+		err := cmd.parseArgs(sub, routed[i])
+		if err != nil {
+			if i > 0 {
+				return fmt.Errorf("%s: %w", cmd.name, err)
+			}
+			return err
+		}
+	}
+
+	c.chain = chain
+	for _, cmd := range chain[1:] {
+		c.positionals = append(c.positionals, cmd.positionals...)
+	}
+	return nil
+}
+
+// parseArgs parses the given arguments. The indices are the indices of the arguments inside the whole command line.
+func (c *Config) parseArgs(args []string, indices []int) error {
+	reader := c.argumentReader(args)
+	reader.indices = indices
+	defer reader.Close()
+
+	err := c.parse(reader)
+	if err != nil {
+		return err
+	}
+
+	// the destination could be a part of the parent's destination
+	prefix := []string{}
+	for cmd := c; cmd.prefix != nil; cmd = cmd.parent {
+		prefix = append(slices.Clone(cmd.prefix), prefix...)
+		if cmd.parent.origins == nil {
+			cmd.parent.origins = map[string]Origin{}
+		}
+		for path, origin := range c.origins {
+			cmd.parent.origins[c.joinPath(append(slices.Clone(prefix), path))] = origin
+		}
+	}
+	return nil
+}
+
+// route selects the subcommands and assigns the given arguments (their indices) to the commands of the chain.
+func (c *Config) route(args []string) ([]*Config, [][]int) {
+	chain := []*Config{c}
+	routed := [][]int{nil}
+
+	for i := 0; i < len(args); i++ {
+		last := len(chain) - 1
+
+		if args[i] == c.options.prefixLong {
+			// all following arguments are positional arguments of the current command
+			for ; i < len(args); i++ {
+				routed[last] = append(routed[last], i)
+			}
+			break
+		}
+
+		if !isOption(args[i], c.options) {
+			if cmd := chain[last].findCommand(args[i]); cmd != nil {
+				if cmd.activate != nil {
+					cmd.activate()
+				}
+				chain = append(chain, cmd)
+				routed = append(routed, nil)
+				continue
+			}
+			routed[last] = append(routed[last], i)
+			continue
+		}
+
+		if flag, ok := consumedFlag(c.consumedFlags(), args[i], c.options); ok {
+			// the consumed flags belong to the root command (like the profile flag)
+			routed[0] = append(routed[0], i)
+			if args[i] == flag && i+1 < len(args) && !isOption(args[i+1], c.options) {
+				i++
+				routed[0] = append(routed[0], i)
+			}
+			continue
+		}
+
+		target, consumesNext := owner(chain, args[i])
+		routed[target] = append(routed[target], i)
+		if consumesNext && i+1 < len(args) {
+			i++
+			routed[target] = append(routed[target], i)
+		}
+	}
+
+	return chain, routed
+}
+
+// owner returns the index of the nearest command of the chain which knows the given flag. If the flag is a short
+// one which requires a value, the next argument belongs to the flag too.
+func owner(chain []*Config, arg string) (int, bool) {
+	for i := len(chain) - 1; i >= 0; i-- {
+		cmd := chain[i]
+		infos := cmd.commandInfos()
+
+		if strings.HasPrefix(arg, cmd.options.prefixLong) {
+			if infos.known(cmd.argumentPath(arg)) {
+				return i, false
+			}
+			continue
+		}
+
+		key, _, hasValue := strings.Cut(strings.TrimPrefix(arg, cmd.options.prefixShort), string(cmd.options.assignSign))
+		if info := infos.findByShort(key); info != nil {
+			return i, !hasValue && info.sType != "bool" && info.sType != "*bool"
+		}
+	}
+
+	// the current command is responsible for unknown flags
+	return len(chain) - 1, false
+}
+
+// applyCommandDefaults applies the default values to the destinations of the selected subcommands.
+func (c *Config) applyCommandDefaults() {
+	for _, cmd := range c.chain[min(1, len(c.chain)):] {
+		if cmd.prefix == nil && cmd.options.autoApplyDefaults {
+			// commands with prefix are a part of the parent's destination
+			cmd.ApplyDefaults()
+		}
+	}
+}
+
+// helpCommand returns the infos for the help of the given command path.
+func (c *Config) helpCommand(path []string) *fieldInfos {
+	cmd := c
+	var parents []*Config
+	for _, name := range path {
+		sub := cmd.findCommand(name)
+		if sub == nil {
+			break
+		}
+		parents = append(parents, cmd)
+		cmd = sub
+	}
+
+	infos := cmd.commandInfos()
+	for i := len(parents) - 1; i >= 0; i-- {
+		global := parents[i].commandInfos()
+		global.commands = nil
+		if infos.globals == nil {
+			infos.globals = global
+		} else {
+			infos.globals.fi = append(infos.globals.fi, global.fi...)
+		}
+	}
+	return infos
+}
+
+// commandInfos returns the infos of all flags of this command (without the flags of the subcommands).
+func (c *Config) commandInfos() *fieldInfos {
+	infos := c.collectInfos()
+	infos.fi = slices.DeleteFunc(infos.fi, func(info fieldInfo) bool {
+		return info.inCommand()
+	})
+	for _, cmd := range c.commandList() {
+		infos.commands = append(infos.commands, commandInfo{name: cmd.name, usage: cmd.usage})
+	}
+	return infos
+}
@@ -0,0 +1,170 @@
+package yacl
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type serveCommand struct {
+	Port int    `yaml:"port" short:"p" usage:"The port"`
+	Host string `yaml:"host"`
+}
+
+type migrateCommand struct {
+	DryRun bool   `yaml:"dry-run" short:"d"`
+	Target string `yaml:"target" positional:"0"`
+}
+
+type commandConfig struct {
+	Verbose bool            `yaml:"verbose" short:"v" usage:"Verbose output"`
+	Name    string          `yaml:"name" short:"n"`
+	Serve   serveCommand    `yaml:"serve" command:"serve" usage:"Starts the server"`
+	Migrate *migrateCommand `yaml:"migrate" command:"migrate" usage:"Migrates the database"`
+}
+
+func TestConfig_Command_Tagged(t *testing.T) {
+	c := commandConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments("-n", "serve", "serve", "-p", "8080", "--verbose", "--host=localhost"))
+	assert.Equal(t, commandConfig{
+		Verbose: true,
+		Name:    "serve",
+		Serve:   serveCommand{Port: 8080, Host: "localhost"},
+	}, c)
+	assert.Equal(t, []string{"serve"}, toTest.Command())
+
+	o, ok := toTest.Origin("serve.port")
+	assert.True(t, ok)
+	assert.Equal(t, Origin{Kind: OriginArgument, Index: 3}, o)
+
+	o, ok = toTest.Origin("verbose")
+	assert.True(t, ok)
+	assert.Equal(t, Origin{Kind: OriginArgument, Index: 5}, o)
+}
+
+func TestConfig_Command_TaggedPointer(t *testing.T) {
+	c := commandConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments("migrate", "-d", "v2", "-v"))
+	assert.Equal(t, commandConfig{
+		Verbose: true,
+		Migrate: &migrateCommand{DryRun: true, Target: "v2"},
+	}, c)
+	assert.Equal(t, []string{"migrate"}, toTest.Command())
+	assert.Equal(t, []string{"v2"}, toTest.Positionals())
+}
+
+func TestConfig_Command_NotSelected(t *testing.T) {
+	c := commandConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments("--verbose", "--", "serve"))
+	assert.Equal(t, commandConfig{Verbose: true}, c)
+	assert.Empty(t, toTest.Command())
+	assert.Equal(t, []string{"serve"}, toTest.Positionals())
+}
+
+func TestConfig_Command_NotSelected_CommandFlags(t *testing.T) {
+	c := commandConfig{}
+	assert.NoError(t, NewConfig(&c).ParseArguments("--serve.port=8080", "--verbose"))
+	assert.Equal(t, commandConfig{Verbose: true}, c)
+
+	c = commandConfig{}
+	err := NewConfig(&c, WithStrict(true)).ParseArguments("--serve.port=8080", "--migrate", "--verbose")
+	assert.EqualError(t, err, "unknown keys: --serve.port=8080 (argument #0), --migrate (argument #1)")
+	assert.Equal(t, commandConfig{}, c)
+}
+
+func TestConfig_Command_FailedParse(t *testing.T) {
+	c := commandConfig{}
+	toTest := NewConfig(&c, WithStrict(true))
+
+	assert.NoError(t, toTest.ParseArguments("serve"))
+	assert.Equal(t, []string{"serve"}, toTest.Command())
+
+	assert.Error(t, toTest.ParseArguments("migrate", "--unknown"))
+	assert.Empty(t, toTest.Command())
+}
+
+func TestConfig_Command_Yaml(t *testing.T) {
+	c := commandConfig{}
+	toTest := NewConfig(&c, WithStrict(true))
+
+	assert.NoError(t, toTest.Load(
+		FileSource(writeFile(t, "config.yaml", "serve:\n  port: 80\n  host: example.com")),
+		ArgumentSource("serve", "--port=8080"),
+	))
+	assert.Equal(t, serveCommand{Port: 8080, Host: "example.com"}, c.Serve)
+	assert.Equal(t, []string{"serve"}, toTest.Command())
+}
+
+func TestNewCommand(t *testing.T) {
+	global := struct {
+		Verbose bool `yaml:"verbose" short:"v"`
+	}{}
+	add := struct {
+		Name string `yaml:"name" positional:"0"`
+		URL  string `yaml:"url" short:"u"`
+	}{}
+
+	root := NewConfig(&global)
+	remote := NewCommand(root, "remote", "Manages remotes", &struct{}{})
+	NewCommand(remote, "add", "Adds a remote", &add)
+
+	assert.NoError(t, root.ParseArguments("remote", "-v", "add", "origin", "-u", "http://example.com"))
+	assert.True(t, global.Verbose)
+	assert.Equal(t, "origin", add.Name)
+	assert.Equal(t, "http://example.com", add.URL)
+	assert.Equal(t, []string{"remote", "add"}, root.Command())
+}
+
+func TestNewCommand_Strict(t *testing.T) {
+	global := struct {
+		Verbose bool `yaml:"verbose"`
+	}{}
+	serve := serveCommand{}
+
+	root := NewConfig(&global, WithStrict(true))
+	NewCommand(root, "serve", "", &serve)
+
+	err := root.ParseArguments("serve", "--portt=80")
+
+	var ukErr *UnknownKeyError
+	assert.True(t, errors.As(err, &ukErr))
+	assert.Equal(t, "serve: unknown keys: --portt=80 (argument #1) did you mean --port?", err.Error())
+}
+
+func TestConfig_Command_HelpFlags(t *testing.T) {
+	c := commandConfig{}
+	toTest := NewConfig(&c)
+
+	expected := `  -v, --verbose=bool
+      	Verbose output
+  -n, --name=string
+
+Commands:
+  serve    Starts the server
+  migrate  Migrates the database
+`
+	assert.Equal(t, expected, toTest.HelpFlags())
+
+	expected = `Usage: [options] <target>
+
+  -d, --dry-run=bool
+      --target=string
+
+Global options:
+  -v, --verbose=bool
+      	Verbose output
+  -n, --name=string
+`
+	assert.Equal(t, expected, toTest.HelpFlags(WithCommand("migrate")))
+
+	// by default the help of the selected command is returned
+	assert.NoError(t, toTest.ParseArguments("serve"))
+	assert.Contains(t, toTest.HelpFlags(), "--port=int")
+	assert.NotContains(t, toTest.HelpFlags(WithCommand()), "--port=int")
+}
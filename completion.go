@@ -0,0 +1,506 @@
+package yacl
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// CompleteArg is the hidden argument which is used by the completion scripts to ask the program for dynamic
+// completions (see WithDynamicCompletion and Config.HandleCompletion).
+const CompleteArg = "__complete"
+
+// completionFlag contains all information of a flag which are necessary for the completion.
+type completionFlag struct {
+	long   string
+	short  string
+	usage  string
+	isBool bool
+	values []string
+	// complete is the value of the complete tag ("file" or "dir")
+	complete string
+}
+
+// completionScope contains the flags and subcommands which can be completed after a command (and its parents) is
+// selected.
+type completionScope struct {
+	// name is the program name followed by the names of the selected subcommands (e.g. "my-app serve")
+	name     string
+	flags    []completionFlag
+	commands []commandInfo
+}
+
+// completionScopes returns the scopes of this config and of all its subcommands.
+func (c *Config) completionScopes() []completionScope {
+	scopes := []completionScope{c.completionScope()}
+	for _, sub := range c.commandList() {
+		scopes = append(scopes, sub.completionScopes()...)
+	}
+	return scopes
+}
+
+// completionScope returns the scope of this config: its own flags, the flags of its parents (they are accepted
+// after the subcommand too) and its subcommands.
+func (c *Config) completionScope() completionScope {
+	scope := completionScope{
+		name:     c.options.programName,
+		commands: c.commandInfos().commands,
+	}
+
+	var names []string
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.parent != nil {
+			names = append([]string{cmd.name}, names...)
+		}
+
+		for _, info := range cmd.commandInfos().fi {
+			if slices.ContainsFunc(scope.flags, func(f completionFlag) bool { return f.long == info.flag(c.options) }) {
+				continue
+			}
+
+			flag := completionFlag{
+				long:     info.flag(c.options),
+				usage:    strings.Split(info.path.Usage(), "\n")[0],
+				isBool:   strings.TrimPrefix(info.sType, "*") == "bool",
+				values:   oneOfValues(info.field.Tag.Get(c.options.validateTag)),
+				complete: info.field.Tag.Get(c.options.completeTag),
+			}
+			if info.short != "" {
+				flag.short = c.options.prefixShort + info.short
+			}
+			scope.flags = append(scope.flags, flag)
+		}
+	}
+	scope.name = strings.Join(append([]string{scope.name}, names...), " ")
+
+	return scope
+}
+
+// selectedCommand returns the subcommand which is selected by the given arguments (see Config.ParseArguments). In
+// contrast to the parsing, the destination struct is not touched.
+func (c *Config) selectedCommand(args []string) *Config {
+	selected := c
+	for _, arg := range args {
+		if arg == c.options.prefixLong {
+			break
+		}
+		if isOption(arg, c.options) {
+			continue
+		}
+		if cmd := selected.findCommand(arg); cmd != nil {
+			selected = cmd
+		}
+	}
+	return selected
+}
+
+// oneOfValues returns the values of the oneof rule of the given validation rules (see Config.Validate).
+func oneOfValues(rules string) []string {
+	for _, rule := range strings.Split(rules, ",") {
+		if values, found := strings.CutPrefix(strings.TrimSpace(rule), "oneof="); found {
+			return strings.Split(values, "|")
+		}
+	}
+	return nil
+}
+
+// candidate returns the completion candidate of the flag. Flags which expect a value end with the assign sign.
+func (f completionFlag) candidate(options Options) string {
+	if f.isBool {
+		return f.long
+	}
+	return f.long + string(options.assignSign)
+}
+
+// CompletionScript generates a completion script for the given shell ("bash", "zsh" or "fish"). The script
+// completes all flags (including the short flags and the templates of maps and slices), the subcommands, the
+// values of the oneof validation rule (see Config.Validate) and files or directories for fields which are tagged
+// with `complete:"file"` or `complete:"dir"` (see WithCompleteTag). Only the flags and subcommands of the selected
+// subcommand (and the flags of its parents) are completed. The name of the program is given by WithProgramName.
+func (c *Config) CompletionScript(shell string) (string, error) {
+	scopes := c.completionScopes()
+
+	switch shell {
+	case "bash":
+		return c.bashCompletion(scopes), nil
+	case "zsh":
+		return c.zshCompletion(scopes), nil
+	case "fish":
+		return c.fishCompletion(scopes), nil
+	default:
+		return "", fmt.Errorf("unsupported shell: %s", shell)
+	}
+}
+
+var reNoIdentifier = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// completionFunction returns the name of the shell function for the completion.
+func (c *Config) completionFunction() string {
+	return "_" + reNoIdentifier.ReplaceAllString(c.options.programName, "_") + "_completion"
+}
+
+// shellQuote quotes the given string for bash and zsh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellWords(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = shellQuote(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellCommandSelection returns the part of the bash and zsh scripts which selects the subcommands by the previous
+// arguments (the array "args"). The result is the name of the scope (the variable "cmd").
+func (c *Config) shellCommandSelection(scopes []completionScope) string {
+	var sb strings.Builder
+	var patterns []string
+	for _, scope := range scopes[1:] {
+		patterns = append(patterns, shellQuote(scope.name))
+	}
+
+	sb.WriteString("\n\t# the previous arguments select the subcommand\n")
+	sb.WriteString(fmt.Sprintf("\tlocal cmd=%s arg\n", shellQuote(scopes[0].name)))
+	sb.WriteString("\tfor arg in \"${args[@]}\"; do\n")
+	sb.WriteString(fmt.Sprintf("\t\t[[ \"$arg\" == %s ]] && break\n", shellQuote(c.options.prefixLong)))
+	sb.WriteString("\t\tcase \"$cmd $arg\" in\n")
+	sb.WriteString(fmt.Sprintf("\t\t%s) cmd=\"$cmd $arg\" ;;\n", strings.Join(patterns, "|")))
+	sb.WriteString("\t\tesac\n")
+	sb.WriteString("\tdone\n")
+	return sb.String()
+}
+
+// writeShellScopes writes the completion of each scope (see writeScope) into the given builder. If there are
+// subcommands, the scope is selected by the variable "cmd" (see shellCommandSelection).
+func writeShellScopes(sb *strings.Builder, scopes []completionScope, writeScope func(sb *strings.Builder, scope completionScope, indent string)) {
+	if len(scopes) == 1 {
+		writeScope(sb, scopes[0], "\t")
+		return
+	}
+
+	sb.WriteString("\tcase \"$cmd\" in\n")
+	for _, scope := range scopes {
+		sb.WriteString(fmt.Sprintf("\t%s)\n", shellQuote(scope.name)))
+		writeScope(sb, scope, "\t\t")
+		sb.WriteString("\t\t;;\n")
+	}
+	sb.WriteString("\tesac\n")
+}
+
+func (c *Config) bashCompletion(scopes []completionScope) string {
+	var sb strings.Builder
+	assign := string(c.options.assignSign)
+
+	sb.WriteString(fmt.Sprintf("# bash completion for %s\n", c.options.programName))
+	sb.WriteString(fmt.Sprintf("%s() {\n", c.completionFunction()))
+	sb.WriteString("\tlocal cur=\"${COMP_LINE:0:COMP_POINT}\"\n")
+	sb.WriteString("\tcur=\"${cur##*[[:space:]]}\"\n")
+	sb.WriteString("\tlocal -a args\n")
+	sb.WriteString("\tread -ra args <<< \"${COMP_LINE:0:COMP_POINT-${#cur}}\"\n")
+	sb.WriteString("\targs=(\"${args[@]:1}\")\n")
+	if len(scopes) > 1 {
+		sb.WriteString(c.shellCommandSelection(scopes))
+	}
+	sb.WriteString("\n")
+
+	writeShellScopes(&sb, scopes, func(sb *strings.Builder, scope completionScope, indent string) {
+		sb.WriteString(indent + "case \"$cur\" in\n")
+		for _, flag := range scope.flags {
+			if flag.isBool || (len(flag.values) == 0 && flag.complete == "") {
+				continue
+			}
+
+			prefix := shellQuote(flag.long + assign)
+			sb.WriteString(fmt.Sprintf("%s%s*)\n", indent, prefix))
+			switch {
+			case len(flag.values) > 0:
+				sb.WriteString(fmt.Sprintf("%s\tmapfile -t COMPREPLY < <(compgen -W %s -P %s -- \"${cur#%s}\")\n",
+					indent, shellQuote(strings.Join(flag.values, " ")), prefix, prefix))
+			case flag.complete == "dir":
+				sb.WriteString(fmt.Sprintf("%s\tmapfile -t COMPREPLY < <(compgen -d -P %s -- \"${cur#%s}\")\n", indent, prefix, prefix))
+			default:
+				sb.WriteString(fmt.Sprintf("%s\tmapfile -t COMPREPLY < <(compgen -f -P %s -- \"${cur#%s}\")\n", indent, prefix, prefix))
+			}
+			sb.WriteString(indent + "\t;;\n")
+		}
+
+		var words []string
+		for _, flag := range scope.flags {
+			words = append(words, flag.candidate(c.options))
+			if flag.short != "" {
+				words = append(words, flag.short)
+			}
+		}
+		for _, cmd := range scope.commands {
+			words = append(words, cmd.name)
+		}
+		sb.WriteString(indent + "*)\n")
+		sb.WriteString(fmt.Sprintf("%s\tlocal words=%s\n", indent, shellQuote(strings.Join(words, " "))))
+		if c.options.dynamicCompletion {
+			sb.WriteString(fmt.Sprintf("%s\twords=\"$words $(%s %s \"${args[@]}\" \"$cur\" 2>/dev/null)\"\n",
+				indent, shellQuote(c.options.programName), CompleteArg))
+		}
+		sb.WriteString(indent + "\tmapfile -t COMPREPLY < <(compgen -W \"$words\" -- \"$cur\")\n")
+		sb.WriteString(indent + "\t;;\n")
+		sb.WriteString(indent + "esac\n")
+	})
+	sb.WriteString("\n")
+
+	sb.WriteString("\t# bash replaces only the part after the last word break (see COMP_WORDBREAKS)\n")
+	sb.WriteString("\tlocal breaks=\"${cur%\"${cur##*[=:]}\"}\"\n")
+	sb.WriteString("\tif [[ -n \"$breaks\" ]]; then\n")
+	sb.WriteString("\t\tCOMPREPLY=(\"${COMPREPLY[@]#\"$breaks\"}\")\n")
+	sb.WriteString("\tfi\n\n")
+	sb.WriteString("\t# the value of a flag follows directly\n")
+	sb.WriteString(fmt.Sprintf("\tif [[ ${#COMPREPLY[@]} -eq 1 && \"${COMPREPLY[0]}\" == *%s ]]; then\n", shellQuote(assign)))
+	sb.WriteString("\t\tcompopt -o nospace 2>/dev/null\n")
+	sb.WriteString("\tfi\n")
+	sb.WriteString("}\n")
+	sb.WriteString(fmt.Sprintf("complete -F %s %s\n", c.completionFunction(), shellQuote(c.options.programName)))
+
+	return sb.String()
+}
+
+// reZshPattern matches all special characters of zsh patterns.
+var reZshPattern = regexp.MustCompile(`[\[\]*?()<>|#^~]`)
+
+func (c *Config) zshCompletion(scopes []completionScope) string {
+	var sb strings.Builder
+	assign := string(c.options.assignSign)
+
+	sb.WriteString(fmt.Sprintf("#compdef %s\n", c.options.programName))
+	sb.WriteString(fmt.Sprintf("# zsh completion for %s\n", c.options.programName))
+	sb.WriteString(fmt.Sprintf("%s() {\n", c.completionFunction()))
+	sb.WriteString("\tlocal cur=\"${words[CURRENT]}\"\n")
+	sb.WriteString("\tlocal -a args=(\"${(@)words[2,CURRENT-1]}\")\n")
+	if len(scopes) > 1 {
+		sb.WriteString(c.shellCommandSelection(scopes))
+	}
+
+	if c.options.dynamicCompletion {
+		sb.WriteString("\n\tlocal -a dynamic\n")
+		sb.WriteString(fmt.Sprintf("\tdynamic=(\"${(@f)$(%s %s \"${args[@]}\" \"$cur\" 2>/dev/null)}\")\n",
+			shellQuote(c.options.programName), CompleteArg))
+		sb.WriteString(fmt.Sprintf("\tcompadd -S '' -- ${(M)dynamic:#*%s}\n", assign))
+		sb.WriteString(fmt.Sprintf("\tcompadd -- ${dynamic:#*%s}\n", assign))
+	}
+	sb.WriteString("\n")
+
+	writeShellScopes(&sb, scopes, func(sb *strings.Builder, scope completionScope, indent string) {
+		for _, flag := range scope.flags {
+			if flag.isBool || (len(flag.values) == 0 && flag.complete == "") {
+				continue
+			}
+
+			sb.WriteString(fmt.Sprintf("%sif compset -P %s; then\n", indent, shellQuote(reZshPattern.ReplaceAllString(flag.long+assign, `\$0`))))
+			switch {
+			case len(flag.values) > 0:
+				sb.WriteString(fmt.Sprintf("%s\tcompadd -- %s\n", indent, shellWords(flag.values)))
+			case flag.complete == "dir":
+				sb.WriteString(indent + "\t_files -/\n")
+			default:
+				sb.WriteString(indent + "\t_files\n")
+			}
+			sb.WriteString(indent + "\treturn\n")
+			sb.WriteString(indent + "fi\n")
+		}
+
+		var valueFlags, otherWords []string
+		for _, flag := range scope.flags {
+			if flag.isBool {
+				otherWords = append(otherWords, flag.long)
+			} else {
+				valueFlags = append(valueFlags, flag.candidate(c.options))
+			}
+			if flag.short != "" {
+				otherWords = append(otherWords, flag.short)
+			}
+		}
+		for _, cmd := range scope.commands {
+			otherWords = append(otherWords, cmd.name)
+		}
+
+		if len(valueFlags) > 0 {
+			sb.WriteString(fmt.Sprintf("%scompadd -S '' -- %s\n", indent, shellWords(valueFlags)))
+		}
+		if len(otherWords) > 0 {
+			sb.WriteString(fmt.Sprintf("%scompadd -- %s\n", indent, shellWords(otherWords)))
+		}
+	})
+	sb.WriteString("}\n")
+	sb.WriteString(fmt.Sprintf("compdef %s %s\n", c.completionFunction(), shellQuote(c.options.programName)))
+
+	return sb.String()
+}
+
+// fishQuote quotes the given string for fish.
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// fishCommandFunction returns the name of the fish function which checks if the given scope is selected.
+func (c *Config) fishCommandFunction() string {
+	return c.completionFunction() + "_command"
+}
+
+func (c *Config) fishCompletion(scopes []completionScope) string {
+	var sb strings.Builder
+	prog := fishQuote(c.options.programName)
+
+	sb.WriteString(fmt.Sprintf("# fish completion for %s\n", c.options.programName))
+	if len(scopes) > 1 {
+		var patterns []string
+		for _, scope := range scopes[1:] {
+			patterns = append(patterns, fishQuote(scope.name))
+		}
+
+		// checks if the previous arguments select the given subcommand
+		sb.WriteString(fmt.Sprintf("function %s\n", c.fishCommandFunction()))
+		sb.WriteString(fmt.Sprintf("\tset -l cmd %s\n", fishQuote(scopes[0].name)))
+		sb.WriteString("\tfor arg in (commandline -opc)[2..-1]\n")
+		sb.WriteString(fmt.Sprintf("\t\ttest \"$arg\" = %s; and break\n", fishQuote(c.options.prefixLong)))
+		sb.WriteString("\t\tswitch \"$cmd $arg\"\n")
+		sb.WriteString(fmt.Sprintf("\t\t\tcase %s\n", strings.Join(patterns, " ")))
+		sb.WriteString("\t\t\t\tset cmd \"$cmd $arg\"\n")
+		sb.WriteString("\t\tend\n")
+		sb.WriteString("\tend\n")
+		sb.WriteString("\ttest \"$cmd\" = \"$argv\"\n")
+		sb.WriteString("end\n")
+	}
+	sb.WriteString(fmt.Sprintf("complete -c %s -f\n", prog))
+
+	for _, scope := range scopes {
+		command := fmt.Sprintf("complete -c %s", prog)
+		if len(scopes) > 1 {
+			command += " -n " + fishQuote(c.fishCommandFunction()+" "+fishQuote(scope.name))
+		}
+
+		for _, flag := range scope.flags {
+			line := command
+
+			if long, ok := strings.CutPrefix(flag.long, "--"); ok && c.options.prefixLong == "--" {
+				line += " -l " + fishQuote(long)
+			} else {
+				line += " -o " + fishQuote(strings.TrimPrefix(flag.long, "-"))
+			}
+			if short, ok := strings.CutPrefix(flag.short, "-"); ok && len(short) == 1 {
+				line += " -s " + fishQuote(short)
+			}
+
+			switch {
+			case flag.isBool:
+			case len(flag.values) > 0:
+				line += " -x -a " + fishQuote(strings.Join(flag.values, " "))
+			case flag.complete == "dir":
+				line += " -x -a '(__fish_complete_directories)'"
+			case flag.complete != "":
+				line += " -r -F"
+			default:
+				line += " -x"
+			}
+
+			if flag.usage != "" {
+				line += " -d " + fishQuote(flag.usage)
+			}
+			sb.WriteString(line + "\n")
+		}
+
+		for _, cmd := range scope.commands {
+			line := command + " -a " + fishQuote(cmd.name)
+			if cmd.usage != "" {
+				line += " -d " + fishQuote(strings.Split(cmd.usage, "\n")[0])
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	if c.options.dynamicCompletion {
+		dynamic := fmt.Sprintf("(%s %s (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)", prog, CompleteArg)
+		sb.WriteString(fmt.Sprintf("complete -c %s -a %s\n", prog, fishQuote(dynamic)))
+	}
+
+	return sb.String()
+}
+
+// Complete returns the completion candidates for the given word (the argument under the cursor). In addition to
+// the flags (see CompletionScript), the candidates contain the flags of the current values of the destination
+// struct (e.g. the known map keys of a loaded config file) and the values of the oneof validation rule. Only the
+// flags and subcommands of this command (and the flags of its parents) are candidates.
+func (c *Config) Complete(word string) []string {
+	var result []string
+	add := func(candidate string) {
+		if strings.HasPrefix(candidate, word) && !slices.Contains(result, candidate) {
+			result = append(result, candidate)
+		}
+	}
+
+	assign := string(c.options.assignSign)
+
+	if key, _, found := strings.Cut(word, assign); found {
+		for cmd := c; cmd != nil; cmd = cmd.parent {
+			_, info := cmd.commandInfos().normalizePath(cmd.splitKey(strings.TrimPrefix(key, cmd.options.prefixLong)))
+			if info != nil {
+				for _, value := range oneOfValues(info.field.Tag.Get(cmd.options.validateTag)) {
+					add(key + assign + value)
+				}
+				break
+			}
+		}
+		return result
+	}
+
+	scope := c.completionScope()
+	for _, flag := range scope.flags {
+		add(flag.candidate(c.options))
+		if flag.short != "" {
+			add(flag.short)
+		}
+	}
+	for _, cmd := range scope.commands {
+		add(cmd.name)
+	}
+
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		infos := cmd.commandInfos()
+		cmd.walkValues(reflect.ValueOf(cmd.dest), nil, func(path []string, v reflect.Value) {
+			if !infos.known(path) {
+				// the value belongs to a subcommand
+				return
+			}
+			if v.Kind() == reflect.Bool {
+				add(cmd.flagOf(path))
+			} else {
+				add(cmd.flagOf(path) + assign)
+			}
+		})
+	}
+
+	return result
+}
+
+// HandleCompletion handles the dynamic completion requests of the completion scripts (see WithDynamicCompletion).
+// If the first of the given arguments is CompleteArg, the completion candidates for the last argument are written
+// line by line to the given writer (see Config.Complete) and true is returned. The arguments between them select
+// the subcommand whose candidates are written. The program should exit afterward.
+func (c *Config) HandleCompletion(w io.Writer, args ...string) bool {
+	if len(args) == 0 || args[0] != CompleteArg {
+		return false
+	}
+
+	word := ""
+	var previous []string
+	if len(args) > 1 {
+		word = args[len(args)-1]
+		previous = args[1 : len(args)-1]
+	}
+	for _, candidate := range c.selectedCommand(previous).Complete(word) {
+		fmt.Fprintln(w, candidate)
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package yacl
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type completionConfig struct {
+	Verbose  bool                 `yaml:"verbose" short:"v" usage:"Verbose output"`
+	Level    string               `yaml:"level" validate:"oneof=debug|info|warn"`
+	Config   string               `yaml:"config" complete:"file"`
+	Data     string               `yaml:"data" complete:"dir"`
+	Map      map[string]testEntry `yaml:"map"`
+	Replicas []struct {
+		Host string `yaml:"host"`
+	} `yaml:"replicas"`
+	Serve *struct {
+		Port int `yaml:"port" short:"p"`
+	} `yaml:"serve" command:"serve" usage:"Starts the server"`
+}
+
+func TestConfig_CompletionScript_Bash(t *testing.T) {
+	c := completionConfig{}
+	script, err := NewConfig(&c, WithProgramName("my-app")).CompletionScript("bash")
+
+	assert.NoError(t, err)
+	assert.Contains(t, script, "_my_app_completion() {\n")
+	assert.Contains(t, script, "\t\tcase \"$cmd $arg\" in\n\t\t'my-app serve') cmd=\"$cmd $arg\" ;;\n")
+	assert.Contains(t, script, "\t'my-app')\n\t\tcase \"$cur\" in\n")
+	assert.Contains(t, script, `local words='--verbose -v --level= --config= --data= --map[string].key= --map[string].value= --replicas[int].host= serve'`)
+	assert.Contains(t, script, "\t'my-app serve')\n\t\tcase \"$cur\" in\n")
+	assert.Contains(t, script, `local words='--port= -p --verbose -v --level= --config= --data= --map[string].key= --map[string].value= --replicas[int].host='`)
+	assert.Contains(t, script, `compgen -W 'debug info warn' -P '--level=' -- "${cur#'--level='}"`)
+	assert.Contains(t, script, `compgen -f -P '--config='`)
+	assert.Contains(t, script, `compgen -d -P '--data='`)
+	assert.Contains(t, script, "complete -F _my_app_completion 'my-app'\n")
+	assert.NotContains(t, script, CompleteArg)
+}
+
+func TestConfig_CompletionScript_Zsh(t *testing.T) {
+	c := completionConfig{}
+	script, err := NewConfig(&c, WithProgramName("my-app"), WithDynamicCompletion(true)).CompletionScript("zsh")
+
+	assert.NoError(t, err)
+	assert.Contains(t, script, "#compdef my-app\n")
+	assert.Contains(t, script, "\t\tif compset -P '--level='; then\n\t\t\tcompadd -- 'debug' 'info' 'warn'\n")
+	assert.Contains(t, script, "\t\tif compset -P '--data='; then\n\t\t\t_files -/\n")
+	assert.Contains(t, script, "\t'my-app')\n")
+	assert.Contains(t, script, "compadd -S '' -- '--level=' '--config=' '--data=' '--map[string].key='")
+	assert.Contains(t, script, "compadd -- '--verbose' '-v' 'serve'\n")
+	assert.Contains(t, script, "\t'my-app serve')\n")
+	assert.Contains(t, script, "compadd -S '' -- '--port=' '--level='")
+	assert.Contains(t, script, "compadd -- '-p' '--verbose' '-v'\n")
+	assert.Contains(t, script, `$('my-app' __complete "${args[@]}" "$cur" 2>/dev/null)`)
+}
+
+func TestConfig_CompletionScript_Fish(t *testing.T) {
+	c := completionConfig{}
+	script, err := NewConfig(&c, WithProgramName("my-app"), WithDynamicCompletion(true)).CompletionScript("fish")
+
+	root := `complete -c 'my-app' -n '_my_app_completion_command \'my-app\''`
+	serve := `complete -c 'my-app' -n '_my_app_completion_command \'my-app serve\''`
+
+	assert.NoError(t, err)
+	assert.Contains(t, script, "function _my_app_completion_command\n")
+	assert.Contains(t, script, "\t\t\tcase 'my-app serve'\n")
+	assert.Contains(t, script, root+" -l 'verbose' -s 'v' -d 'Verbose output'\n")
+	assert.Contains(t, script, root+" -l 'level' -x -a 'debug info warn'\n")
+	assert.Contains(t, script, root+" -l 'config' -r -F\n")
+	assert.Contains(t, script, root+" -l 'map[string].value' -x -d 'The value of the entry'\n")
+	assert.Contains(t, script, root+" -a 'serve' -d 'Starts the server'\n")
+	assert.NotContains(t, script, root+" -l 'port'")
+	assert.Contains(t, script, serve+" -l 'port' -s 'p' -x\n")
+	assert.Contains(t, script, serve+" -l 'verbose' -s 'v' -d 'Verbose output'\n")
+	assert.Contains(t, script, `complete -c 'my-app' -a '(\'my-app\' __complete (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)'`+"\n")
+}
+
+func TestConfig_CompletionScript_Fish_ProgramName(t *testing.T) {
+	c := struct {
+		Verbose bool `yaml:"verbose"`
+	}{}
+	script, err := NewConfig(&c, WithProgramName("my app's"), WithDynamicCompletion(true)).CompletionScript("fish")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `# fish completion for my app's
+complete -c 'my app\'s' -f
+complete -c 'my app\'s' -l 'verbose'
+complete -c 'my app\'s' -a '(\'my app\\\'s\' __complete (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)'
+`, script)
+}
+
+func TestConfig_CompletionScript_Unsupported(t *testing.T) {
+	c := completionConfig{}
+	_, err := NewConfig(&c).CompletionScript("powershell")
+	assert.EqualError(t, err, "unsupported shell: powershell")
+}
+
+func TestConfig_Complete(t *testing.T) {
+	c := completionConfig{Map: map[string]testEntry{"known": {Key: "k"}}}
+	c.Serve = &struct {
+		Port int `yaml:"port" short:"p"`
+	}{Port: 80}
+	toTest := NewConfig(&c)
+
+	assert.Equal(t, []string{"--map[string].key=", "--map[string].value=", "--map[known].key=", "--map[known].value="}, toTest.Complete("--map"))
+	assert.Equal(t, []string{"--level=debug"}, toTest.Complete("--level=d"))
+	assert.Equal(t, []string{"--verbose"}, toTest.Complete("--verb"))
+	assert.Equal(t, []string{"serve"}, toTest.Complete("s"))
+	assert.Empty(t, toTest.Complete("--unknown"))
+	assert.Empty(t, toTest.Complete("--p"))
+	assert.Empty(t, toTest.Complete("--serve"))
+}
+
+func TestConfig_HandleCompletion(t *testing.T) {
+	c := completionConfig{}
+	toTest := NewConfig(&c)
+
+	buf := bytes.Buffer{}
+	assert.False(t, toTest.HandleCompletion(&buf, "--level=info"))
+	assert.Empty(t, buf.String())
+
+	assert.True(t, toTest.HandleCompletion(&buf, CompleteArg, "--verbose", "--level="))
+	assert.Equal(t, "--level=debug\n--level=info\n--level=warn\n", buf.String())
+
+	buf.Reset()
+	assert.True(t, toTest.HandleCompletion(&buf, CompleteArg, "--verbose", "serve", "--p"))
+	assert.Equal(t, "--port=\n", buf.String())
+	assert.Nil(t, c.Serve)
+
+	buf.Reset()
+	assert.True(t, toTest.HandleCompletion(&buf, CompleteArg, "--", "serve", "--p"))
+	assert.Empty(t, buf.String())
+}
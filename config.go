@@ -1,17 +1,56 @@
 package yacl
 
 import (
+	"errors"
 	"fmt"
 	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
 	"io"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 )
 
 type Config struct {
 	options Options
 
 	dest any
+
+	origins     map[string]Origin
+	positionals []string
+
+	// name and usage of the (sub)command
+	name  string
+	usage string
+
+	parent   *Config
+	commands []*Config
+	// chain contains the selected command chain (beginning with this config) of the last parsed arguments
+	chain []*Config
+	// prefix is the path to the destination inside the parent's destination (for commands defined by tag)
+	prefix []string
+	// activate sets the destination inside the parent's destination (for nil pointers)
+	activate func()
+
+	// sources contains the sources of the last Load (used by Watch)
+	sources []Source
+	// loaded is true if the config was loaded by Load, parsed is true if it was parsed by any other function (like
+	// ParseArguments). Only loaded configs which are not parsed otherwise can be watched (see Watch).
+	loaded bool
+	parsed bool
+	// including contains the files which are currently parsed (to detect include cycles)
+	including []string
+
+	// profiles contains the active profiles, profile is the profile whose overlay file is currently parsed
+	profiles []string
+	profile  string
+	// pathFlags contains the path flags of the discovery sources of the last Load (see consumedFlags)
+	pathFlags []string
+	// profileLayers apply the values of a profile to the files which were parsed before (see activateProfiles)
+	profileLayers []func(profile string) error
 }
 
 // NewConfig creates a new Config instance where all parse-results will be reflected in the given destination.
@@ -25,13 +64,156 @@ func NewConfig[T any](destination *T, opts ...Option) *Config {
 	for _, opt := range opts {
 		opt(&p.options)
 	}
+	p.profiles = slices.Clone(p.options.activeProfiles)
 
 	return p
 }
 
 // ParseYaml parses the given YAML reader and sets the values in the destination struct.
+// The origin of each value is recorded (see Config.Origin). If the reader has a name (like os.File)
+// it is used as file name.
+// After parsing, the values which are set are validated (see WithAutoValidate). The other values (like required
+// ones) are validated by ParseArguments and Load, because they can be given by the arguments afterward.
 func (c *Config) ParseYaml(reader io.Reader) error {
-	return yaml.NewDecoder(reader, c.options.decodeOptions...).Decode(c.dest)
+	c.parsed = true
+
+	err := c.parseYaml(reader)
+	if err != nil {
+		return err
+	}
+
+	if c.options.autoValidate {
+		return c.validateSet()
+	}
+	return nil
+}
+
+func (c *Config) parseYaml(reader io.Reader) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	return c.parseContent(content, reader, originResolver(reader))
+}
+
+// parseContent parses the given yaml content. The reader is the origin of the content, the given function resolves
+// the origin of each line.
+func (c *Config) parseContent(content []byte, reader any, resolve func(line int) Origin) error {
+	file, err := parser.ParseBytes(content, 0)
+	if err != nil {
+		return err
+	}
+
+	var body ast.Node
+	if len(file.Docs) > 0 {
+		body = file.Docs[0].Body
+	}
+
+	if r, ok := reader.(*Reader); ok && r.envNames == nil {
+		c.positionals = r.positionalArgs()
+	}
+
+	included := map[*token.Token]string{}
+	base, isFile := includeBaseOf(reader)
+	if isFile && c.options.includes && body != nil {
+		if base.name != "" {
+			leave, err := c.enterInclude(base)
+			if err != nil {
+				return err
+			}
+			defer leave()
+		}
+
+		if err := c.extend(body, base); err != nil {
+			return err
+		}
+		if body, err = c.include(body, base, included); err != nil {
+			return err
+		}
+	}
+
+	var sections *ast.MappingNode
+	if isFile && c.options.profiles {
+		if sections, err = c.profileSections(body); err != nil {
+			return err
+		}
+	}
+
+	origin := tokenOrigin(resolve, included)
+	if err := c.decodeContent(body, reader, origin, included, c.profile); err != nil {
+		return err
+	}
+	if sections == nil {
+		return nil
+	}
+
+	// the sections of the active profiles override the values of the file
+	applySection := func(profile string) error {
+		for _, mv := range sections.Values {
+			if yamlKey(mv.Key) != profile {
+				continue
+			}
+			if err := c.decodeContent(mv.Value, reader, origin, included, profile); err != nil && err != io.EOF {
+				return err
+			}
+		}
+		return nil
+	}
+	if c.profile == "" {
+		c.profileLayers = append(c.profileLayers, applySection)
+	}
+	for _, profile := range c.profiles {
+		if err := applySection(profile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeContent decodes the given yaml node into the destination struct and records the origins of the values. If
+// a profile is given, the values are recorded as values of this profile.
+func (c *Config) decodeContent(
+	body ast.Node, reader any, origin func(tk *token.Token) Origin, included map[*token.Token]string, profile string,
+) error {
+	if profile != "" {
+		profileOrigin := origin
+		origin = func(tk *token.Token) Origin {
+			o := profileOrigin(tk)
+			o.Profile = profile
+			return o
+		}
+	}
+
+	if c.options.lookupEnv != nil && body != nil {
+		if err := c.interpolateBody(&body, reader, included); err != nil {
+			return err
+		}
+	}
+
+	decodeOptions := append(scalarDecodeOptions(), c.options.decodeOptions...)
+	if c.options.strict {
+		if err := c.checkUnknownKeys(body, reader, origin); err != nil {
+			return err
+		}
+		decodeOptions = append(decodeOptions, yaml.DisallowUnknownField())
+	}
+
+	if body == nil {
+		return io.EOF
+	}
+
+	before := c.values()
+	_, isReader := reader.(*Reader)
+	err := c.decodeScalars(&body, isReader, func() error {
+		return yaml.NodeToValue(body, c.dest, decodeOptions...)
+	})
+	if err != nil {
+		return c.collectInfos().maskError(err, body)
+	}
+
+	c.recordOrigins(body, origin, before)
+	return nil
 }
 
 // ParseOsArguments parses the command line arguments (os.Args[1:]) and sets the values in the destination struct.
@@ -40,43 +222,104 @@ func (c *Config) ParseOsArguments() error {
 }
 
 // ParseArguments parses the given arguments and sets the values in the destination struct.
+// If there are subcommands (see NewCommand), the first positional argument which matches the name of a
+// subcommand selects it (see Config.Command). After the default values are applied, the references are resolved
+// (see Config.Resolve), the required fields are checked (see Config.CheckRequired) and the values are validated
+// (see WithAutoValidate). The errors of these steps are returned together. If the arguments select profiles (see
+// WithProfiles), they are applied to the files which were parsed before.
 func (c *Config) ParseArguments(args ...string) error {
-	reader := c.ArgumentReader(args...)
-	defer reader.Close()
+	c.parsed = true
 
-	err := c.ParseYaml(reader)
-	if err != nil && err != io.EOF {
-		// ignore EOF error (it would be occurred if no args are given)
+	if err := c.activateProfiles(c.profilesOfArgs(args)); err != nil {
+		return err
+	}
+
+	err := c.parseCommandLine(args)
+	if err != nil {
 		return err
 	}
 
 	if c.options.autoApplyDefaults {
 		c.ApplyDefaults()
 	}
+	c.applyCommandDefaults()
 
-	return nil
+	return c.checkValues()
+}
+
+// checkValues resolves the references (see Config.Resolve), checks the required fields (see Config.CheckRequired)
+// and validates the values (see WithAutoValidate). All steps are run even if one fails; their errors are returned
+// together.
+func (c *Config) checkValues() error {
+	errs := []error{c.Resolve(), c.CheckRequired()}
+	if c.options.autoValidate {
+		errs = append(errs, c.Validate())
+	}
+	return errors.Join(errs...)
+}
+
+// Positionals returns all positional arguments (arguments which are not options) of the last parsed arguments.
+// All arguments after "--" are treated as positional arguments.
+func (c *Config) Positionals() []string {
+	return slices.Clone(c.positionals)
 }
 
 // ArgumentReader creates a new reader that reads the given arguments and transform them into yaml-format.
 func (c *Config) ArgumentReader(args ...string) io.ReadCloser {
-	infos := c.collectInfos()
-	reader := newReader(args, infos, c.options)
+	return c.argumentReader(args)
+}
+
+func (c *Config) argumentReader(args []string) *Reader {
+	reader := newReader(args, c.collectInfos(), c.options)
+	reader.consumed = c.consumedFlags()
 	return reader
 }
 
+// consumedFlags returns the flags which are not a part of the destination struct, but are consumed by the config
+// itself: the flag which selects the profiles (see WithProfiles) and the path flags of the discovery sources of the
+// last Load (see WithPathFlag).
+func (c *Config) consumedFlags() []string {
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	var flags []string
+	if root.options.profiles {
+		flags = append(flags, root.profileFlag())
+	}
+	return append(flags, root.pathFlags...)
+}
+
 // ParseOsEnvironment parses the environment variables (os.Environ()) and sets the values in the destination struct.
 func (c *Config) ParseOsEnvironment() error {
 	return c.ParseEnvironment(os.Environ()...)
 }
 
 // ParseEnvironment parses the given environment variables and sets the values in the destination struct.
+// If the environment selects profiles (see WithProfiles), they are applied to the files which were parsed before.
 func (c *Config) ParseEnvironment(env ...string) error {
+	c.parsed = true
+
+	if err := c.activateProfiles(c.profilesOfEnv(env)); err != nil {
+		return err
+	}
+
 	reader := c.EnvironmentReader(env...)
+	if reader == nil {
+		return nil
+	}
 	defer reader.Close()
 
-	err := c.ParseYaml(reader)
+	return c.parse(reader)
+}
+
+// parse parses the given reader. The format is detected by the name of the reader (see ParseFile), the default
+// format is yaml. In contrast to ParseYaml an empty content is not treated as error and the values are not validated.
+func (c *Config) parse(reader io.Reader) error {
+	err := c.parseFormat(reader)
 	if err != nil && err != io.EOF {
-		// ignore EOF error (it would be occurred if no environments are given)
+		// ignore EOF error (it would be occurred if the reader has no content)
 		return err
 	}
 	return nil
@@ -95,34 +338,69 @@ func (e *errorReader) Close() error {
 }
 
 // EnvironmentReader creates a new reader that reads the given environment variables and transform them into yaml-format.
+// How the environment variables are interpreted depends on the env mode (see WithEnvMode).
 func (c *Config) EnvironmentReader(env ...string) io.ReadCloser {
+	if c.options.envMode == EnvModeNamed {
+		args, names := c.namedEnvironment(env)
+		reader := newReader(args, c.collectInfos(), c.options)
+		reader.envNames = names
+		return reader
+	}
+
 	if c.options.prefixEnv == "" {
 		return nil
 	}
 
-	re, err := regexp.Compile(`^` + c.options.prefixEnv + `[^=]*=(.*)$`)
+	re, err := regexp.Compile(`(?s)^` + c.options.prefixEnv + `[^=]*=(.*)$`)
 	if err != nil {
 		return &errorReader{err: fmt.Errorf("invalid env variable prefix: %w", err)}
 	}
 
 	// transform env to args
 	args := make([]string, 0, len(env))
+	names := make([]string, 0, len(env))
 	for _, e := range env {
+		if c.options.profiles && strings.HasPrefix(e, c.profileEnv()+"=") {
+			// the variable selects the profiles (see WithProfiles)
+			continue
+		}
+
 		r := re.FindAllStringSubmatch(e, -1)
 		if len(r) == 1 {
 			args = append(args, r[0][1])
+			names = append(names, strings.SplitN(e, "=", 2)[0])
 		}
 	}
 
-	return c.ArgumentReader(args...)
+	reader := newReader(args, c.collectInfos(), c.options)
+	reader.envNames = names
+	return reader
 }
 
 // HelpFlags returns the help text for the flags in a table format. Sorted by the order in struct.
+// If there are subcommands, they are listed too. The help of a subcommand (see WithCommand) contains the flags of
+// the subcommand and the global flags of its parents. By default, the help of the selected subcommand is returned.
 func (c *Config) HelpFlags(opts ...HelpOption) string {
-	return c.help(opts...).HelpFlags()
+	options := newDefaultHelpOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	path := options.command
+	if path == nil {
+		path = c.Command()
+	}
+
+	infos := c.helpCommand(path)
+	options.apply(infos)
+	if infos.globals != nil {
+		options.apply(infos.globals)
+	}
+	return infos.HelpFlags() + c.helpProfiles()
 }
 
-// HelpYaml returns the help text for the flags in a YAML format. Sorted by the order in struct.
+// HelpYaml returns the help text for the flags in a YAML format. Sorted by the order in struct. For a loadable
+// sample configuration file see SampleYaml.
 func (c *Config) HelpYaml(opts ...HelpOption) string {
 	return c.help(opts...).HelpYaml()
 }
@@ -134,13 +412,7 @@ func (c *Config) help(opts ...HelpOption) *fieldInfos {
 	for _, opt := range opts {
 		opt(&options)
 	}
-
-	if options.sorter != nil {
-		infos.Sort(options.sorter)
-	}
-	if options.filter != nil {
-		infos.Filter(options.filter)
-	}
+	options.apply(infos)
 
 	return infos
 }
@@ -158,6 +158,20 @@ func TestConfig_Parse_PrimitiveArray_Single(t *testing.T) {
 	}, conf)
 }
 
+func TestConfig_Parse_IntegerMapKeys(t *testing.T) {
+	type entry struct {
+		V string `yaml:"v"`
+	}
+	conf := struct {
+		M  map[int]string `yaml:"m"`
+		MI map[int]entry  `yaml:"mi"`
+	}{}
+
+	assert.NoError(t, NewConfig(&conf).ParseArguments("--m[1]=a", "--mi[3].v=v"))
+	assert.Equal(t, map[int]string{1: "a"}, conf.M)
+	assert.Equal(t, map[int]entry{3: {V: "v"}}, conf.MI)
+}
+
 func TestConfig_ParseEnv(t *testing.T) {
 	conf := testConfig{}
 
@@ -7,13 +7,16 @@ type DefaultSetter interface {
 }
 
 // ApplyDefaults applies default values (execute all DefaultSetters) to the fields of the destination struct.
+// All values which are changed by the DefaultSetters are recorded with the origin OriginDefault.
 func (c *Config) ApplyDefaults() {
 	v := reflect.ValueOf(c.dest)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
 
-	c.applyDefaultsRecursive(v.Type(), v)
+	c.recordDefaultOrigins(func() {
+		c.applyDefaultsRecursive(v.Type(), v)
+	})
 }
 
 func (c *Config) applyDefaultsRecursive(t reflect.Type, v reflect.Value) {
@@ -97,7 +100,7 @@ func (c *Config) getDefaultValue(parentType reflect.Type, field reflect.StructFi
 		typeVal = reflect.New(parentType).Interface()
 		goDefaultValue := reflect.ValueOf(typeVal).Elem().FieldByName(field.Name).Interface()
 
-		if userDefinedDefaultValue != goDefaultValue {
+		if !reflect.DeepEqual(userDefinedDefaultValue, goDefaultValue) {
 			return userDefinedDefaultValue, true
 		}
 	}
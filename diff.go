@@ -0,0 +1,106 @@
+package yacl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change describes a changed value of the destination struct.
+type Change struct {
+	// Path is the flag of the changed value (e.g. "--server.port", "--map[key]", "--array[0].key").
+	Path string
+
+	// Old is the previous value (nil if the value did not exist before). Secret values are masked (see SecretMask).
+	Old any
+
+	// New is the current value (nil if the value does not exist anymore). Secret values are masked (see SecretMask).
+	New any
+}
+
+// Diff returns all values which are different between the given values. Both values are walked into pointers,
+// slices and maps. Each Change contains the flag of the value (e.g. "--server.port", "--map[key]"), the old value
+// (from a) and the new value (from b). Secret values are masked (see SecretMask). The options are the same as for
+// NewConfig (e.g. to use other tags or prefixes).
+func Diff[T any](a, b *T, opts ...Option) []Change {
+	return NewConfig(a, opts...).changes(a, b)
+}
+
+// DiffText renders the given changes as text (one line per change), e.g. for logging:
+//
+//	--server.port  80 -> 8080
+//	--tags[1]      <unset> -> "b"
+func DiffText(changes []Change) string {
+	var sb strings.Builder
+
+	maxLen := 0
+	for _, change := range changes {
+		maxLen = max(maxLen, len(change.Path))
+	}
+
+	for _, change := range changes {
+		sb.WriteString("  ")
+		sb.WriteString(change.Path)
+		sb.WriteString(strings.Repeat(" ", maxLen-len(change.Path)+2))
+		sb.WriteString(diffValue(change.Old))
+		sb.WriteString(" -> ")
+		sb.WriteString(diffValue(change.New))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func diffValue(v any) string {
+	if v == nil {
+		return "<unset>"
+	}
+
+	value := dumpValue(reflect.ValueOf(v))
+	if s, ok := v.(string); ok && s != SecretMask {
+		// make leading/trailing whitespaces and empty strings visible
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
+
+// changes returns all (leaf) values which are different between the given values (of the destination type).
+func (c *Config) changes(old, new any) []Change {
+	infos := c.collectInfos()
+	secrets := map[string]bool{}
+
+	var flags []string
+	values := func(v any) map[string]any {
+		result := map[string]any{}
+		c.walkValues(reflect.ValueOf(v), nil, func(path []string, v reflect.Value) {
+			flag := c.flagOf(path)
+			if _, known := secrets[flag]; !known {
+				flags = append(flags, flag)
+				secrets[flag] = infos.isSecret(path) || isSecretType(v.Type())
+			}
+			result[flag] = v.Interface()
+		})
+		return result
+	}
+	oldValues, newValues := values(old), values(new)
+
+	var result []Change
+	for _, flag := range flags {
+		o, inOld := oldValues[flag]
+		n, inNew := newValues[flag]
+		if inOld && inNew && reflect.DeepEqual(o, n) {
+			continue
+		}
+
+		if secrets[flag] {
+			if inOld {
+				o = SecretMask
+			}
+			if inNew {
+				n = SecretMask
+			}
+		}
+		result = append(result, Change{Path: flag, Old: o, New: n})
+	}
+	return result
+}
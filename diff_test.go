@@ -0,0 +1,62 @@
+package yacl
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	a := newDumpConfig()
+	b := newDumpConfig()
+
+	b.Server.Port = 9090
+	b.IntP = nil
+	b.Duration = time.Minute
+	b.Tags = b.Tags[:11]
+	b.Entries[1].Value = "value1"
+	b.Map["other"] = testEntry{Key: "other"}
+	b.RawMap = map[string]any{"list": []any{"a", "c"}}
+
+	// the changes are ordered like the fields of the old value, added values are at the end
+	assert.Equal(t, []Change{
+		{Path: "--intP", Old: 0, New: nil},
+		{Path: "--duration", Old: 90 * time.Second, New: time.Minute},
+		{Path: "--tags[11]", Old: "tag11", New: nil},
+		{Path: "--entry[1].value", Old: "", New: "value1"},
+		{Path: "--raw-map[deep][key]", Old: "value", New: nil},
+		{Path: "--raw-map[list][1]", Old: "b", New: "c"},
+		{Path: "--server.port", Old: 8080, New: 9090},
+		{Path: "--map[other].key", Old: nil, New: "other"},
+		{Path: "--map[other].value", Old: nil, New: ""},
+	}, Diff(&a, &b))
+
+	assert.Empty(t, Diff(&a, &a))
+}
+
+func TestDiff_Secret(t *testing.T) {
+	a := newSecretConfig()
+	b := newSecretConfig()
+	b.Password = "changed"
+	b.Token.Set("changed")
+	b.Keys["new"] = "key"
+
+	assert.Equal(t, []Change{
+		{Path: "--password", Old: SecretMask, New: SecretMask},
+		{Path: "--token", Old: SecretMask, New: SecretMask},
+		{Path: "--keys[new]", Old: nil, New: SecretMask},
+	}, Diff(&a, &b))
+}
+
+func TestDiffText(t *testing.T) {
+	assert.Equal(t, `  --server.port  80 -> 8080
+  --tags[1]      <unset> -> "b"
+  --password     ****** -> ******
+  --timeout      1s -> <unset>
+`, DiffText([]Change{
+		{Path: "--server.port", Old: 80, New: 8080},
+		{Path: "--tags[1]", New: "b"},
+		{Path: "--password", Old: SecretMask, New: SecretMask},
+		{Path: "--timeout", Old: time.Second},
+	}))
+}
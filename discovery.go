@@ -0,0 +1,192 @@
+package yacl
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Discovery is a source which searches the configuration files of an application (see DiscoverySource).
+type Discovery struct {
+	name    string
+	options DiscoveryOptions
+
+	mutex  sync.Mutex
+	loaded []string
+}
+
+// DiscoverySource creates a source which searches the configuration files of the application with the given name
+// in the search paths (see WithSearchPaths). By default, only the first found file is loaded (see WithMergeAll). The
+// search can be overridden by a flag (see WithPathFlag) or an environment variable (see WithPathEnv). If no file is
+// found, nothing is loaded. The files are parsed like Config.ParseFile, so the format is detected by the extension.
+func DiscoverySource(name string, opts ...DiscoveryOption) *Discovery {
+	d := &Discovery{name: name}
+	for _, opt := range opts {
+		opt(&d.options)
+	}
+	return d
+}
+
+func (d *Discovery) Name() string {
+	return d.name
+}
+
+func (d *Discovery) Precedence() Precedence {
+	return PrecedenceFile
+}
+
+// Reader returns a reader of the first found file. Note that Config.Load applies all found files (see WithMergeAll).
+func (d *Discovery) Reader(c *Config) (io.ReadCloser, error) {
+	sources, err := d.sources(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return sources[len(sources)-1].Reader(c)
+}
+
+// Loaded returns the paths of the files which were loaded by the last Config.Load (in order of loading).
+func (d *Discovery) Loaded() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return slices.Clone(d.loaded)
+}
+
+// SearchPaths returns the (expanded) paths which are searched in order of their priority.
+func (d *Discovery) SearchPaths() []string {
+	paths := d.options.searchPaths
+	if paths == nil {
+		paths = defaultSearchPaths(d.name)
+	}
+
+	var result []string
+	for _, path := range paths {
+		if path = expandPath(path); path != "" {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// sources returns the sources of the files which should be loaded (in order of loading). The path flag is parsed
+// like the arguments of the given Config (prefix and assign sign).
+func (d *Discovery) sources(c *Config) ([]Source, error) {
+	d.mutex.Lock()
+	d.loaded = nil
+	d.mutex.Unlock()
+
+	if path := d.overriddenPath(c.options); path != "" {
+		return []Source{d.fileSource(path)}, nil
+	}
+
+	var found []Source
+	for _, path := range d.SearchPaths() {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		found = append(found, d.fileSource(path))
+		if !d.options.mergeAll {
+			break
+		}
+	}
+
+	// the first found file has the highest priority, so it must be applied at last
+	slices.Reverse(found)
+	return found, nil
+}
+
+// overriddenPath returns the path which is given by the flag or the environment variable (if any). The arguments are
+// interpreted by the given options (e.g. "/config:path" if the long prefix is "/" and the assign sign is ':').
+func (d *Discovery) overriddenPath(options Options) string {
+	if d.options.pathFlag != "" {
+		for i, arg := range d.options.args {
+			if arg == options.prefixLong {
+				// the options are terminated
+				break
+			}
+			if value, ok := strings.CutPrefix(arg, d.options.pathFlag+string(options.assignSign)); ok {
+				return value
+			}
+			if arg == d.options.pathFlag && i+1 < len(d.options.args) && !isOption(d.options.args[i+1], options) {
+				return d.options.args[i+1]
+			}
+		}
+	}
+	if d.options.pathEnv != "" {
+		if value, ok := os.LookupEnv(d.options.pathEnv); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// fileSource creates a FileSource for the given path which records the path as loaded.
+func (d *Discovery) fileSource(path string) Source {
+	return &source{
+		name:       path,
+		precedence: PrecedenceFile,
+		reader: func(*Config) (io.ReadCloser, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+
+			d.mutex.Lock()
+			d.loaded = append(d.loaded, path)
+			d.mutex.Unlock()
+			return f, nil
+		},
+	}
+}
+
+// defaultSearchPaths returns the default search paths of the application with the given name (see WithSearchPaths).
+func defaultSearchPaths(name string) []string {
+	paths := []string{
+		name + ".yaml",
+		filepath.Join(envOr("XDG_CONFIG_HOME", "~/.config"), name, "config.yaml"),
+		filepath.Join("~", "."+name+".yaml"),
+	}
+	for _, dir := range filepath.SplitList(envOr("XDG_CONFIG_DIRS", "/etc/xdg")) {
+		paths = append(paths, filepath.Join(dir, name, "config.yaml"))
+	}
+	return append(paths, filepath.Join("/etc", name, "config.yaml"))
+}
+
+// envOr returns the value of the given environment variable or the given fallback if it is not set.
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// expandPath expands the environment variables and a leading "~" of the given path. If the home directory is
+// unknown, an empty string is returned for paths which are relative to it.
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
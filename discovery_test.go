@@ -0,0 +1,182 @@
+package yacl
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeDiscoveryFiles(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first", "config.yaml")
+	second := filepath.Join(dir, "second.yaml")
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(first), 0755))
+	assert.NoError(t, os.WriteFile(first, []byte("file: first\n"), 0644))
+	assert.NoError(t, os.WriteFile(second, []byte("file: second\nmap: second\n"), 0644))
+	return first, second
+}
+
+func TestDiscovery_FirstFound(t *testing.T) {
+	first, second := writeDiscoveryFiles(t)
+	missing := filepath.Join(filepath.Dir(second), "missing.yaml")
+
+	c := loadConfig{}
+	toTest := DiscoverySource("app", WithSearchPaths(missing, first, second))
+
+	assert.NoError(t, NewConfig(&c).Load(toTest))
+	assert.Equal(t, "first", c.File)
+	assert.Equal(t, "", c.Map)
+	assert.Equal(t, []string{first}, toTest.Loaded())
+}
+
+func TestDiscovery_MergeAll(t *testing.T) {
+	first, second := writeDiscoveryFiles(t)
+
+	c := loadConfig{}
+	toTest := DiscoverySource("app", WithSearchPaths(first, second), WithMergeAll(true))
+	config := NewConfig(&c)
+
+	assert.NoError(t, config.Load(toTest))
+	assert.Equal(t, "first", c.File)
+	assert.Equal(t, "second", c.Map)
+	assert.Equal(t, []string{second, first}, toTest.Loaded())
+
+	origin, found := config.Origin("--file")
+	assert.True(t, found)
+	assert.Equal(t, first+":1", origin.String())
+}
+
+func TestDiscovery_NotFound(t *testing.T) {
+	c := loadConfig{}
+	toTest := DiscoverySource("app", WithSearchPaths(filepath.Join(t.TempDir(), "missing.yaml")))
+
+	assert.NoError(t, NewConfig(&c).Load(toTest))
+	assert.Empty(t, toTest.Loaded())
+	assert.Equal(t, 1, c.defaultsApplied)
+}
+
+func TestDiscovery_PathFlag(t *testing.T) {
+	first, second := writeDiscoveryFiles(t)
+
+	for _, args := range [][]string{
+		{"--arg=arg", "--config=" + second},
+		{"--config", second, "--arg=arg"},
+	} {
+		c := loadConfig{}
+		toTest := DiscoverySource("app", WithSearchPaths(first), WithPathFlag("--config", args...))
+
+		assert.NoError(t, NewConfig(&c).Load(toTest))
+		assert.Equal(t, "second", c.File)
+		assert.Equal(t, []string{second}, toTest.Loaded())
+	}
+}
+
+func TestDiscovery_PathFlag_Arguments(t *testing.T) {
+	first, second := writeDiscoveryFiles(t)
+
+	for _, args := range [][]string{
+		{"--arg=arg", "--config=" + second},
+		{"--config", second, "--arg=arg"},
+	} {
+		c := loadConfig{}
+		toTest := NewConfig(&c, WithStrict(true))
+		discovery := DiscoverySource("app", WithSearchPaths(first), WithPathFlag("--config", args...))
+
+		// the flag (and its value) is consumed by the discovery
+		assert.NoError(t, toTest.Load(discovery, ArgumentSource(args...)))
+		assert.Equal(t, "second", c.File)
+		assert.Equal(t, "arg", c.Arg)
+		assert.Empty(t, toTest.Positionals())
+	}
+}
+
+func TestDiscovery_PathFlag_CustomOptions(t *testing.T) {
+	first, second := writeDiscoveryFiles(t)
+
+	for _, args := range [][]string{
+		{"//arg:arg", "//config:" + second},
+		{"//config", second, "//arg:arg"},
+	} {
+		c := loadConfig{}
+		toTest := NewConfig(&c, WithPrefixLong("//"), WithAssignSign(':'), WithStrict(true))
+		discovery := DiscoverySource("app", WithSearchPaths(first), WithPathFlag("//config", args...))
+
+		assert.NoError(t, toTest.Load(discovery, ArgumentSource(args...)))
+		assert.Equal(t, "second", c.File)
+		assert.Equal(t, "arg", c.Arg)
+		assert.Equal(t, []string{second}, discovery.Loaded())
+	}
+
+	// the options are terminated by the prefix
+	c := loadConfig{}
+	discovery := DiscoverySource("app", WithSearchPaths(first), WithPathFlag("//config", "//", "//config:"+second))
+	assert.NoError(t, NewConfig(&c, WithPrefixLong("//"), WithAssignSign(':')).Load(discovery))
+	assert.Equal(t, "first", c.File)
+}
+
+func TestDiscovery_PathEnv(t *testing.T) {
+	first, second := writeDiscoveryFiles(t)
+	t.Setenv("APP_CONFIG", second)
+
+	c := loadConfig{}
+	toTest := DiscoverySource("app", WithSearchPaths(first), WithPathEnv("APP_CONFIG"))
+
+	assert.NoError(t, NewConfig(&c).Load(toTest))
+	assert.Equal(t, "second", c.File)
+
+	// the flag has a higher priority
+	c = loadConfig{}
+	toTest = DiscoverySource("app", WithPathFlag("--config", "--config="+first), WithPathEnv("APP_CONFIG"))
+
+	assert.NoError(t, NewConfig(&c).Load(toTest))
+	assert.Equal(t, "first", c.File)
+}
+
+func TestDiscovery_PathEnv_Missing(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	t.Setenv("APP_CONFIG", missing)
+
+	c := loadConfig{}
+	toTest := DiscoverySource("app", WithPathEnv("APP_CONFIG"))
+
+	assert.ErrorContains(t, NewConfig(&c).Load(toTest), "app: "+missing+": open "+missing)
+}
+
+func TestDiscovery_Reader(t *testing.T) {
+	first, second := writeDiscoveryFiles(t)
+
+	c := loadConfig{}
+	toTest := DiscoverySource("app", WithSearchPaths(first, second), WithMergeAll(true))
+	config := NewConfig(&c)
+
+	reader, err := toTest.Reader(config)
+	assert.NoError(t, err)
+	assert.NoError(t, config.parse(reader))
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, "first", c.File)
+	assert.Equal(t, "", c.Map)
+}
+
+func TestDiscovery_SearchPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CONFIG_DIRS", "/opt/xdg:/usr/xdg")
+
+	assert.Equal(t, []string{
+		"app.yaml",
+		filepath.Join(home, ".config", "app", "config.yaml"),
+		filepath.Join(home, ".app.yaml"),
+		"/opt/xdg/app/config.yaml",
+		"/usr/xdg/app/config.yaml",
+		"/etc/app/config.yaml",
+	}, DiscoverySource("app").SearchPaths())
+
+	t.Setenv("APP_DIR", "/srv/app")
+	assert.Equal(t, []string{
+		"/srv/app/config.yaml",
+		filepath.Join(home, "app.yaml"),
+	}, DiscoverySource("app", WithSearchPaths("$APP_DIR/config.yaml", "~/app.yaml")).SearchPaths())
+}
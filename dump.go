@@ -0,0 +1,153 @@
+package yacl
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DumpYaml writes the current values of the destination struct in yaml-format to the given writer.
+// The output can be parsed by ParseYaml. The values of secret fields are replaced by SecretMask.
+func (c *Config) DumpYaml(w io.Writer) error {
+	content, err := c.collectInfos().maskedYaml(c.dest)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(content)
+	return err
+}
+
+// DumpArgs returns the current values of the destination struct as arguments (e.g. "--server.port=80",
+// "--map[key].value=v", "--array[0].key=k"). The output can be parsed by ParseArguments. The values of secret
+// fields are replaced by SecretMask. Assign signs, brackets and backslashes inside map keys are escaped by a backslash
+// (e.g. "--map[a\=b]=v").
+func (c *Config) DumpArgs() []string {
+	var result []string
+	infos := c.collectInfos()
+	c.walkValues(reflect.ValueOf(c.dest), nil, func(path []string, v reflect.Value) {
+		escaped := make([]string, len(path))
+		for i, segment := range path {
+			escaped[i] = segment
+			if key, isKey := strings.CutPrefix(segment, "["); isKey {
+				escaped[i] = "[" + escapeKey(strings.TrimSuffix(key, "]"), c.options.assignSign) + "]"
+			}
+		}
+		result = append(result, c.flagOf(escaped)+string(c.options.assignSign)+infos.dumpValue(path, v))
+	})
+	return result
+}
+
+// DumpEnv returns the current values of the destination struct as environment variables (e.g. "CFG_0=--server.port=80"
+// or "CFG_SERVER_PORT=80" depending on the env mode, see WithEnvMode). The output can be parsed by ParseEnvironment.
+// The values of secret fields are replaced by SecretMask. In EnvModeNamed an error is returned if a map key can not
+// be a part of the name of an environment variable: silently dropping such entries would break the round trip. The
+// returned variables contain all other values anyway.
+func (c *Config) DumpEnv() ([]string, error) {
+	var result []string
+
+	if c.options.envMode != EnvModeNamed {
+		for i, arg := range c.DumpArgs() {
+			result = append(result, fmt.Sprintf("%s%d=%s", c.options.prefixEnv, i, arg))
+		}
+		return result, nil
+	}
+
+	infos := c.collectInfos()
+	var errs []error
+	c.walkValues(reflect.ValueOf(c.dest), nil, func(path []string, v reflect.Value) {
+		name, err := c.envNameOf(path, infos)
+		if err != nil {
+			errs = append(errs, FieldError{Flag: c.flagOf(path), Err: err})
+			return
+		}
+		result = append(result, name+"="+infos.dumpValue(path, v))
+	})
+	return result, errors.Join(errs...)
+}
+
+var reEnvName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// envNameOf returns the name of the environment variable for the given normalized path (see EnvModeNamed).
+func (c *Config) envNameOf(path []string, infos *fieldInfos) (string, error) {
+	if _, info := infos.normalizePath(path); info != nil {
+		if name := info.envTag(c.options); name != "" {
+			return name, nil
+		}
+	}
+
+	parts := make([]string, len(path))
+	for i, segment := range path {
+		if !strings.HasPrefix(segment, "[") {
+			parts[i] = envPart(segment, c.options)
+			continue
+		}
+
+		// map key or slice index
+		key := strings.TrimSuffix(strings.TrimPrefix(segment, "["), "]")
+		if !reEnvName.MatchString("_" + key) {
+			return "", fmt.Errorf("map key %q can not be a part of an environment variable name", key)
+		}
+		if c.options.envCase == EnvCaseUpper {
+			if !strings.ContainsFunc(key, unicode.IsUpper) {
+				key = strings.ToUpper(key)
+			} else if !strings.ContainsFunc(key, unicode.IsLower) {
+				// upper case keys would be read as lower case keys (see envMapKey)
+				return "", fmt.Errorf("map key %q can not be a part of an environment variable name", key)
+			}
+		}
+		parts[i] = key
+	}
+	return c.options.prefixEnv + strings.Join(parts, c.options.envSeparator), nil
+}
+
+// dumpValue returns the string representation of the value behind the given path. If the value is secret,
+// SecretMask will be returned.
+func (f *fieldInfos) dumpValue(path []string, v reflect.Value) string {
+	if f.isSecret(path) || isSecretType(v.Type()) {
+		return SecretMask
+	}
+	return dumpValue(v)
+}
+
+// dumpValue returns the string representation of the given (leaf) value which can be parsed again.
+func dumpValue(v reflect.Value) string {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+
+	if st, ok := lookupScalarType(v.Type()); ok {
+		return formatScalar(st, v)
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,196 @@
+package yacl
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type dumpConfig struct {
+	String   string               `yaml:"string"`
+	Quoted   string               `yaml:"quoted"`
+	Int      int                  `yaml:"int"`
+	IntP     *int                 `yaml:"intP"`
+	Float    float64              `yaml:"float"`
+	Bool     bool                 `yaml:"bool"`
+	Duration time.Duration        `yaml:"duration"`
+	Time     time.Time            `yaml:"time"`
+	Tags     []string             `yaml:"tags"`
+	Entries  []testEntry          `yaml:"entry"`
+	Map      map[string]testEntry `yaml:"map"`
+	RawMap   map[string]any       `yaml:"raw-map"`
+	Server   struct {
+		Port int `yaml:"port"`
+	} `yaml:"server"`
+}
+
+func newDumpConfig() dumpConfig {
+	c := dumpConfig{
+		String:   "hello world",
+		Quoted:   "it's a key=value: 'quoted' \"string\"",
+		Int:      -42,
+		IntP:     P(0),
+		Float:    1.25,
+		Bool:     true,
+		Duration: 90 * time.Second,
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Entries:  []testEntry{{Key: "key0", Value: "value0"}, {Key: "key1"}},
+		Map:      map[string]testEntry{"my key": {Key: "key", Value: "value"}},
+		RawMap:   map[string]any{"deep": map[string]any{"key": "value"}, "list": []any{"a", "b"}},
+	}
+	for i := 0; i < 12; i++ {
+		c.Tags = append(c.Tags, fmt.Sprintf("tag%d", i))
+	}
+	c.Server.Port = 8080
+	return c
+}
+
+func TestConfig_DumpArgs(t *testing.T) {
+	c := newDumpConfig()
+	args := NewConfig(&c).DumpArgs()
+
+	assert.Contains(t, args, "--string=hello world")
+	assert.Contains(t, args, "--duration=1m30s")
+	assert.Contains(t, args, "--tags[11]=tag11")
+	assert.Contains(t, args, "--entry[1].key=key1")
+	assert.Contains(t, args, "--map[my key].value=value")
+	assert.Contains(t, args, "--raw-map[deep][key]=value")
+	assert.Contains(t, args, "--server.port=8080")
+
+	parsed := dumpConfig{}
+	assert.NoError(t, NewConfig(&parsed).ParseArguments(args...))
+	assert.Equal(t, c, parsed)
+}
+
+func TestConfig_DumpEnv(t *testing.T) {
+	c := newDumpConfig()
+	env, err := NewConfig(&c).DumpEnv()
+	assert.NoError(t, err)
+
+	assert.Contains(t, env, "CFG_0=--string=hello world")
+
+	parsed := dumpConfig{}
+	assert.NoError(t, NewConfig(&parsed).ParseEnvironment(env...))
+	assert.Equal(t, c, parsed)
+}
+
+func TestConfig_DumpEnv_Named(t *testing.T) {
+	c := newDumpConfig()
+	c.RawMap = map[string]any{"key": "value"}
+	c.Map = map[string]testEntry{"key": {Key: "key", Value: "value"}}
+	env, err := NewConfig(&c, WithEnvMode(EnvModeNamed)).DumpEnv()
+	assert.NoError(t, err)
+
+	assert.Contains(t, env, "CFG_SERVER_PORT=8080")
+	assert.Contains(t, env, "CFG_ENTRY_1_KEY=key1")
+	assert.Contains(t, env, "CFG_MAP_KEY_VALUE=value")
+	assert.Contains(t, env, "CFG_TAGS_11=tag11")
+
+	parsed := dumpConfig{}
+	assert.NoError(t, NewConfig(&parsed, WithEnvMode(EnvModeNamed)).ParseEnvironment(env...))
+	assert.Equal(t, c, parsed)
+}
+
+func TestConfig_Dump_RoundTrip(t *testing.T) {
+	c := newDumpConfig()
+	c.String = "null"
+	c.Quoted = "first line\nsecond line\ttab"
+	c.Tags = []string{"0x10", "1e3", "true", "~", "", "+42", "-007"}
+	c.Map = map[string]testEntry{"Up": {Key: "key", Value: "null"}, "down": {Value: "010"}}
+	c.RawMap = map[string]any{"Mixed_Case": "value"}
+
+	t.Run("args", func(t *testing.T) {
+		parsed := dumpConfig{}
+		assert.NoError(t, NewConfig(&parsed).ParseArguments(NewConfig(&c).DumpArgs()...))
+		assert.Equal(t, c, parsed)
+	})
+	t.Run("env", func(t *testing.T) {
+		env, err := NewConfig(&c).DumpEnv()
+		assert.NoError(t, err)
+
+		parsed := dumpConfig{}
+		assert.NoError(t, NewConfig(&parsed).ParseEnvironment(env...))
+		assert.Equal(t, c, parsed)
+	})
+	for _, envCase := range []EnvCase{EnvCaseUpper, EnvCaseLower, EnvCaseKeep} {
+		t.Run(fmt.Sprintf("env named %d", envCase), func(t *testing.T) {
+			env, err := NewConfig(&c, WithEnvMode(EnvModeNamed), WithEnvCase(envCase)).DumpEnv()
+			assert.NoError(t, err)
+
+			parsed := dumpConfig{}
+			assert.NoError(t, NewConfig(&parsed, WithEnvMode(EnvModeNamed), WithEnvCase(envCase)).ParseEnvironment(env...))
+			assert.Equal(t, c, parsed)
+		})
+	}
+}
+
+func TestConfig_Dump_RoundTrip_MapKeys(t *testing.T) {
+	type entry struct {
+		V string `yaml:"v"`
+	}
+	type keysConfig struct {
+		Ints    map[int]string    `yaml:"ints"`
+		Structs map[int]entry     `yaml:"structs"`
+		Strings map[string]int    `yaml:"strings"`
+		Lists   map[int][]string  `yaml:"lists"`
+		Slice   []entry           `yaml:"slice"`
+		Nested  map[string]*entry `yaml:"nested"`
+	}
+
+	c := keysConfig{
+		Ints:    map[int]string{1: "a", 20: "b"},
+		Structs: map[int]entry{3: {V: "v"}},
+		Strings: map[string]int{"x=y": 2, "a]b": 3, `back\slash`: 4, "0": 5, "c.d": 6},
+		Lists:   map[int][]string{7: {"x", "y"}},
+		Slice:   []entry{{V: "first"}, {V: "second"}},
+		Nested:  map[string]*entry{"k=[v]": {V: "n"}},
+	}
+
+	args := NewConfig(&c).DumpArgs()
+	assert.Contains(t, args, `--strings[x\=y]=2`)
+	assert.Contains(t, args, `--strings[a\]b]=3`)
+	assert.Contains(t, args, `--strings[back\\slash]=4`)
+
+	parsed := keysConfig{}
+	assert.NoError(t, NewConfig(&parsed, WithStrict(true)).ParseArguments(args...))
+	assert.Equal(t, c, parsed)
+
+	env, err := NewConfig(&c).DumpEnv()
+	assert.NoError(t, err)
+
+	parsed = keysConfig{}
+	assert.NoError(t, NewConfig(&parsed, WithStrict(true)).ParseEnvironment(env...))
+	assert.Equal(t, c, parsed)
+}
+
+func TestConfig_DumpEnv_Named_InvalidKeys(t *testing.T) {
+	c := dumpConfig{
+		Map:    map[string]testEntry{"k.x": {Value: "value"}},
+		RawMap: map[string]any{"UP": "value"},
+	}
+
+	env, err := NewConfig(&c, WithEnvMode(EnvModeNamed)).DumpEnv()
+	assert.EqualError(t, err, `--map[k.x].key map key "k.x" can not be a part of an environment variable name`+"\n"+
+		`--map[k.x].value map key "k.x" can not be a part of an environment variable name`+"\n"+
+		`--raw-map[UP] map key "UP" can not be a part of an environment variable name`)
+	assert.Contains(t, env, "CFG_INT=0")
+	assert.NotContains(t, env, "CFG_MAP_K.X_VALUE=value")
+
+	// upper case keys are fine if the case of the names is kept
+	_, err = NewConfig(&c, WithEnvMode(EnvModeNamed), WithEnvCase(EnvCaseKeep)).DumpEnv()
+	assert.EqualError(t, err, `--map[k.x].key map key "k.x" can not be a part of an environment variable name`+"\n"+
+		`--map[k.x].value map key "k.x" can not be a part of an environment variable name`)
+}
+
+func TestConfig_DumpYaml(t *testing.T) {
+	c := newDumpConfig()
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, NewConfig(&c).DumpYaml(&buf))
+
+	parsed := dumpConfig{}
+	assert.NoError(t, NewConfig(&parsed).ParseYaml(&buf))
+	assert.Equal(t, c, parsed)
+}
@@ -0,0 +1,174 @@
+package yacl
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// EnvMode describes how environment variables are interpreted.
+type EnvMode int
+
+const (
+	// EnvModeArguments interprets the values of all environment variables (with the env prefix) as arguments.
+	// For example: CFG_0=--server.port=80
+	EnvModeArguments EnvMode = iota
+
+	// EnvModeNamed binds each field to an environment variable whose name is derived from the path of the field.
+	// For example: CFG_SERVER_PORT=80, CFG_MAP_KEY_VALUE=value, CFG_ENTRY_0_VALUE=value
+	EnvModeNamed
+)
+
+// EnvCase describes the case of the derived environment variable names (see EnvModeNamed).
+type EnvCase int
+
+const (
+	EnvCaseUpper EnvCase = iota
+	EnvCaseLower
+	EnvCaseKeep
+)
+
+var reNonAlphaNumeric = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// envName returns the name of the environment variable of the field. Map keys and slice indices are shown as
+// placeholders (e.g. "CFG_MAP_<string>_VALUE").
+func (f *fieldInfo) envName(options Options) string {
+	if name := f.envTag(options); name != "" {
+		return name
+	}
+
+	parts := make([]string, 0, len(f.path))
+	for _, node := range f.path {
+		parts = append(parts, envPart(node.key, options))
+		if node.isSlice && !strings.HasPrefix(f.sType, "[]") {
+			parts = append(parts, "<int>")
+		} else if node.isMap {
+			parts = append(parts, "<"+node.mapKeyType.String()+">")
+		}
+	}
+	return options.prefixEnv + strings.Join(parts, options.envSeparator)
+}
+
+// envTag returns the name which is defined by the env tag. The tag is ignored for fields inside maps and slices.
+func (f *fieldInfo) envTag(options Options) string {
+	for _, node := range f.path[:len(f.path)-1] {
+		if node.isMap || node.isSlice {
+			return ""
+		}
+	}
+	return f.field.Tag.Get(options.envTag)
+}
+
+// envPattern returns the regular expression which matches the names of the environment variables of the field.
+// The sub-matches are the map keys and slice indices.
+func (f *fieldInfo) envPattern(options Options) *regexp.Regexp {
+	if name := f.envTag(options); name != "" {
+		return regexp.MustCompile(`^` + regexp.QuoteMeta(name) + `$`)
+	}
+
+	sep := regexp.QuoteMeta(options.envSeparator)
+	var sb strings.Builder
+	sb.WriteString(`^`)
+	sb.WriteString(regexp.QuoteMeta(options.prefixEnv))
+	for i, node := range f.path {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteString(regexp.QuoteMeta(envPart(node.key, options)))
+
+		if node.isSlice {
+			if i == len(f.path)-1 {
+				// slices of primitives can be set without index too
+				sb.WriteString(`(?:` + sep + `([0-9]+))?`)
+			} else {
+				sb.WriteString(sep + `([0-9]+)`)
+			}
+		} else if node.isMap {
+			sb.WriteString(sep + `(.+?)`)
+		}
+	}
+	sb.WriteString(`$`)
+
+	return regexp.MustCompile(sb.String())
+}
+
+// envPart converts the given key into a part of an environment variable name.
+func envPart(key string, options Options) string {
+	key = reNonAlphaNumeric.ReplaceAllString(key, options.envSeparator)
+	switch options.envCase {
+	case EnvCaseUpper:
+		return strings.ToUpper(key)
+	case EnvCaseLower:
+		return strings.ToLower(key)
+	default:
+		return key
+	}
+}
+
+// envMapKey converts the given part of an environment variable name back into a map key. With EnvCaseUpper only
+// upper case parts are converted into lower case keys, so that mixed case keys (e.g. "CFG_MAP_MyKey") are kept.
+func envMapKey(part string, options Options) string {
+	if options.envCase == EnvCaseUpper && !strings.ContainsFunc(part, unicode.IsLower) {
+		return strings.ToLower(part)
+	}
+	return part
+}
+
+// namedEnvironment transforms the given environment variables into arguments (see EnvModeNamed).
+// It returns the arguments and the names of the corresponding environment variables.
+func (c *Config) namedEnvironment(env []string) ([]string, []string) {
+	infos := c.collectInfos()
+	patterns := make([]*regexp.Regexp, len(infos.fi))
+	for i := range infos.fi {
+		patterns[i] = infos.fi[i].envPattern(c.options)
+	}
+
+	var args, names []string
+	for _, e := range env {
+		name, value, ok := strings.Cut(e, "=")
+		if !ok {
+			continue
+		}
+
+		for i, pattern := range patterns {
+			m := pattern.FindStringSubmatch(name)
+			if m == nil {
+				continue
+			}
+
+			args = append(args, c.options.prefixLong+infos.fi[i].envKey(m[1:], c.options)+string(c.options.assignSign)+value)
+			names = append(names, name)
+			break
+		}
+	}
+
+	return args, names
+}
+
+// envKey returns the key (in argument syntax) of the field for the given map keys and slice indices.
+func (f *fieldInfo) envKey(dynamic []string, options Options) string {
+	var sb strings.Builder
+	for i, node := range f.path {
+		if i > 0 {
+			sb.WriteRune(options.keyDelimiter)
+		}
+		sb.WriteString(node.key)
+
+		if node.isSlice || node.isMap {
+			if len(dynamic) == 0 {
+				continue
+			}
+			part := dynamic[0]
+			dynamic = dynamic[1:]
+			if part == "" {
+				// slice of primitives without index
+				continue
+			}
+			if node.isMap {
+				part = envMapKey(part, options)
+			}
+			sb.WriteString("[" + part + "]")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,178 @@
+package yacl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/goccy/go-yaml"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	formatYaml = "yaml"
+	formatJson = "json"
+	formatToml = "toml"
+)
+
+// formatOf returns the format of the file with the given name (by its extension).
+func formatOf(name string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return formatYaml, true
+	case ".json":
+		return formatJson, true
+	case ".toml":
+		return formatToml, true
+	default:
+		return formatYaml, false
+	}
+}
+
+// ParseJson parses the given JSON reader and sets the values in the destination struct (see ParseYaml).
+// The yaml tags of the destination struct are used for the keys.
+func (c *Config) ParseJson(reader io.Reader) error {
+	c.parsed = true
+
+	err := c.parseJson(reader)
+	if err != nil {
+		return err
+	}
+
+	if c.options.autoValidate {
+		return c.validateSet()
+	}
+	return nil
+}
+
+// ParseToml parses the given TOML reader and sets the values in the destination struct (see ParseYaml).
+// The yaml tags of the destination struct are used for the keys. The origins of the values contain no lines.
+func (c *Config) ParseToml(reader io.Reader) error {
+	c.parsed = true
+
+	err := c.parseToml(reader)
+	if err != nil {
+		return err
+	}
+
+	if c.options.autoValidate {
+		return c.validateSet()
+	}
+	return nil
+}
+
+// ParseFile parses the given file and sets the values in the destination struct. The format is detected by the
+// extension of the file: ".yaml" and ".yml" for yaml, ".json" for JSON and ".toml" for TOML.
+func (c *Config) ParseFile(path string) error {
+	c.parsed = true
+
+	if _, ok := formatOf(path); !ok {
+		return fmt.Errorf("unsupported file format: %s", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = c.parseFormat(f)
+	if err != nil {
+		return err
+	}
+	if err := c.parseProfileOverlays(f); err != nil {
+		return err
+	}
+
+	if c.options.autoValidate {
+		return c.validateSet()
+	}
+	return nil
+}
+
+// ParseFS parses all files of the given file system which match the given pattern (see fs.Glob) in lexical order
+// and sets the values in the destination struct. So the later files override the values of the earlier ones
+// (e.g. "conf.d/10-base.yaml" < "conf.d/20-local.yaml"). The format of each file is detected by its extension
+// (see ParseFile), files with an unknown extension are parsed as yaml. Empty files are ignored as well as a pattern
+// which matches no file. The errors contain the name of the file.
+func (c *Config) ParseFS(fsys fs.FS, pattern string) error {
+	c.parsed = true
+
+	sources, err := fsSources(fsys, pattern)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sources {
+		if err := c.load(s); err != nil {
+			return fmt.Errorf("%s: %w", s.Name(), err)
+		}
+	}
+
+	if c.options.autoValidate {
+		return c.validateSet()
+	}
+	return nil
+}
+
+// parseFormat parses the given reader in the format which is detected by the name of the reader (if any).
+func (c *Config) parseFormat(reader io.Reader) error {
+	format := formatYaml
+	if named, ok := reader.(interface{ Name() string }); ok {
+		format, _ = formatOf(named.Name())
+	}
+
+	switch format {
+	case formatJson:
+		return c.parseJson(reader)
+	case formatToml:
+		return c.parseToml(reader)
+	default:
+		return c.parseYaml(reader)
+	}
+}
+
+func (c *Config) parseJson(reader io.Reader) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(content)) > 0 {
+		// yaml is a superset of json, so we have to check the syntax by our own
+		var v any
+		if err := json.Unmarshal(content, &v); err != nil {
+			return fmt.Errorf("invalid json: %w", err)
+		}
+	}
+
+	return c.parseContent(content, reader, originResolver(reader))
+}
+
+func (c *Config) parseToml(reader io.Reader) error {
+	var values map[string]any
+	_, err := toml.NewDecoder(reader).Decode(&values)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return io.EOF
+	}
+
+	content, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	// the lines of the generated yaml are not the lines of the toml content
+	resolve := originResolver(reader)
+	return c.parseContent(content, reader, func(line int) Origin {
+		o := resolve(line)
+		o.Line = 0
+		return o
+	})
+}
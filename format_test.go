@@ -0,0 +1,148 @@
+package yacl
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConfig_ParseJson(t *testing.T) {
+	c := testConfig{}
+	toTest := NewConfig(&c, WithDefaults(SetDefaults))
+
+	assert.NoError(t, toTest.ParseJson(strings.NewReader(`{
+  "string": "hello",
+  "float": 1.5,
+  "array": [{"key": "name0"}],
+  "map": {"test": {"key": "name"}}
+}`)))
+	assert.Equal(t, "hello", c.String)
+	assert.Equal(t, float32(1.5), c.Float)
+	assert.Equal(t, []testEntry{{Key: "name0", Value: "DEFAULT"}}, c.CustomArray)
+	assert.Equal(t, map[string]testEntry{"test": {Key: "name", Value: "DEFAULT"}}, c.CustomMap)
+
+	o, _ := toTest.Origin("map.test.key")
+	assert.Equal(t, Origin{Kind: OriginFile, Line: 5}, o)
+}
+
+func TestConfig_ParseJson_Invalid(t *testing.T) {
+	c := testConfig{}
+	err := NewConfig(&c).ParseJson(strings.NewReader("string: hello"))
+	assert.ErrorContains(t, err, "invalid json")
+}
+
+func TestConfig_ParseToml(t *testing.T) {
+	c := testConfig{}
+	toTest := NewConfig(&c, WithDefaults(SetDefaults))
+
+	assert.NoError(t, toTest.ParseToml(strings.NewReader(`
+string = "hello"
+float = 1.5
+string-array = ["a", "b"]
+
+[[array]]
+key = "name0"
+
+[map.test]
+key = "name"
+`)))
+	assert.Equal(t, "hello", c.String)
+	assert.Equal(t, float32(1.5), c.Float)
+	assert.Equal(t, []string{"a", "b"}, c.StringArray)
+	assert.Equal(t, []testEntry{{Key: "name0", Value: "DEFAULT"}}, c.CustomArray)
+	assert.Equal(t, map[string]testEntry{"test": {Key: "name", Value: "DEFAULT"}}, c.CustomMap)
+
+	o, _ := toTest.Origin("map.test.key")
+	assert.Equal(t, Origin{Kind: OriginFile}, o)
+}
+
+func TestConfig_ParseToml_Invalid(t *testing.T) {
+	c := testConfig{}
+	assert.Error(t, NewConfig(&c).ParseToml(strings.NewReader("string = ")))
+}
+
+func TestConfig_ParseFile(t *testing.T) {
+	yamlPath := writeFile(t, "config.yml", "string: yaml")
+	jsonPath := writeFile(t, "config.json", `{"string": "json", "bool": true}`)
+	tomlPath := writeFile(t, "config.toml", `string = "toml"`)
+
+	c := testConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseFile(yamlPath))
+	assert.Equal(t, "yaml", c.String)
+
+	assert.NoError(t, toTest.ParseFile(jsonPath))
+	assert.Equal(t, "json", c.String)
+	assert.True(t, c.Bool)
+
+	assert.NoError(t, toTest.ParseFile(tomlPath))
+	assert.Equal(t, "toml", c.String)
+
+	o, _ := toTest.Origin("string")
+	assert.Equal(t, tomlPath, o.String())
+	o, _ = toTest.Origin("bool")
+	assert.Equal(t, jsonPath+":1", o.String())
+
+	assert.ErrorContains(t, toTest.ParseFile(writeFile(t, "config.ini", "")), "unsupported file format")
+}
+
+func TestConfig_Load_Formats(t *testing.T) {
+	c := testConfig{}
+	toTest := NewConfig(&c, WithStrict(true))
+
+	err := toTest.Load(
+		FileSource(writeFile(t, "config.json", `{"string": "json"}`)),
+		FileSource(writeFile(t, "config.toml", "float = 1.5\nfloatt = 2.5")),
+	)
+
+	var ukErr *UnknownKeyError
+	assert.True(t, errors.As(err, &ukErr))
+	assert.Equal(t, "floatt", ukErr.Keys[0].Key)
+	assert.Equal(t, "--float", ukErr.Keys[0].Suggestion)
+	assert.Equal(t, "json", c.String)
+}
+
+func TestConfig_ParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf.d/20-local.json": {Data: []byte(`{"string": "local"}`)},
+		"conf.d/10-base.yaml":  {Data: []byte("string: base\nbool: true\nfloat: 1.5\n")},
+		"conf.d/15-empty.yaml": {Data: []byte{}},
+		"conf.d/30-dir.yaml":   {Mode: fs.ModeDir},
+		"conf.d/README.md":     {Data: []byte("# Fragments")},
+	}
+
+	c := testConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseFS(fsys, "conf.d/*.*ml"))
+	assert.Equal(t, "base", c.String)
+	assert.True(t, c.Bool)
+
+	assert.NoError(t, toTest.ParseFS(fsys, "conf.d/*.json"))
+	assert.Equal(t, "local", c.String)
+	assert.Equal(t, float32(1.5), c.Float)
+
+	o, _ := toTest.Origin("bool")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: "conf.d/10-base.yaml", Line: 2}, o)
+
+	assert.NoError(t, toTest.ParseFS(fsys, "missing/*.yaml"))
+}
+
+func TestConfig_ParseFS_Error(t *testing.T) {
+	fsys := fstest.MapFS{
+		"10-base.yaml":    {Data: []byte("string: base\n")},
+		"20-invalid.yaml": {Data: []byte("string: base\nfloat: [\n")},
+	}
+
+	c := testConfig{}
+	err := NewConfig(&c).ParseFS(fsys, "*.yaml")
+	assert.ErrorContains(t, err, "20-invalid.yaml: [2:8]")
+	assert.Equal(t, "base", c.String)
+
+	assert.ErrorIs(t, NewConfig(&c).ParseFS(fsys, "[*.yaml"), path.ErrBadPattern)
+}
@@ -3,6 +3,7 @@ module github.com/rainu/go-yacl
 go 1.24.2
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/goccy/go-yaml v1.17.1
 	github.com/stretchr/testify v1.10.0
 )
@@ -0,0 +1,345 @@
+package yacl
+
+import (
+	"fmt"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// includeTag is the yaml tag which replaces the tagged value with the content of other files (see WithIncludes).
+	includeTag = "!include"
+
+	// extendsKey is the top-level key which names the files which are parsed before the file (see WithIncludes).
+	extendsKey = "extends"
+)
+
+// includeBase is a file which can contain include directives. Relative paths are resolved against its directory.
+type includeBase struct {
+	// fsys is the file system of the file (nil for the file system of the os)
+	fsys fs.FS
+	name string
+}
+
+// includeBaseOf returns the include base of the given reader. Only (unnamed) yaml content and files can include
+// other files - but not arguments, environment variables or maps.
+func includeBaseOf(reader any) (includeBase, bool) {
+	switch r := reader.(type) {
+	case *Reader:
+		return includeBase{}, false
+	case *namedReader:
+		return includeBase{name: r.name}, r.kind == "" || r.kind == OriginFile
+	case *fsFile:
+		return includeBase{fsys: r.fsys, name: r.name}, true
+	case interface{ Name() string }:
+		return includeBase{name: r.Name()}, true
+	}
+	return includeBase{}, true
+}
+
+// key returns the key of the file which identifies it while detecting cycles.
+func (b includeBase) key() string {
+	if b.fsys != nil {
+		return b.name
+	}
+	if abs, err := filepath.Abs(b.name); err == nil {
+		return abs
+	}
+	return b.name
+}
+
+// files returns the files which are addressed by the given path. The path is relative to the directory of the base
+// file and can contain a glob pattern. The matching files are returned in lexical order.
+func (b includeBase) files(p string) ([]includeBase, error) {
+	var name string
+	if b.fsys != nil {
+		name = path.Join(path.Dir(b.name), p)
+		if path.IsAbs(p) {
+			name = path.Clean(strings.TrimPrefix(p, "/"))
+		}
+	} else {
+		name = filepath.Join(filepath.Dir(b.name), p)
+		if filepath.IsAbs(p) {
+			name = filepath.Clean(p)
+		}
+	}
+
+	if !strings.ContainsAny(p, "*?[") {
+		return []includeBase{{fsys: b.fsys, name: name}}, nil
+	}
+
+	var names []string
+	var err error
+	if b.fsys != nil {
+		names, err = fs.Glob(b.fsys, name)
+	} else {
+		names, err = filepath.Glob(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+
+	result := make([]includeBase, 0, len(names))
+	for _, n := range names {
+		result = append(result, includeBase{fsys: b.fsys, name: n})
+	}
+	return result, nil
+}
+
+func (b includeBase) open() (io.ReadCloser, error) {
+	if b.fsys == nil {
+		return os.Open(b.name)
+	}
+
+	file, err := b.fsys.Open(b.name)
+	if err != nil {
+		return nil, err
+	}
+	return &fsFile{File: file, name: b.name, fsys: b.fsys}, nil
+}
+
+// enterInclude marks the given file as currently parsed. It returns an error if the file is already parsed (which
+// would result in an endless loop). The returned function must be called after the file is parsed.
+func (c *Config) enterInclude(b includeBase) (func(), error) {
+	key := b.key()
+	if slices.Contains(c.including, key) {
+		return nil, fmt.Errorf("include cycle detected: %s -> %s", strings.Join(c.including, " -> "), key)
+	}
+
+	c.including = append(c.including, key)
+	return func() {
+		c.including = c.including[:len(c.including)-1]
+	}, nil
+}
+
+// extend parses the files which are named by the top-level extends key of the given body and removes the key.
+// The files are parsed in the given order before the body, so the values of the body override their values.
+func (c *Config) extend(body ast.Node, base includeBase) error {
+	mapping, ok := body.(*ast.MappingNode)
+	if !ok {
+		return nil
+	}
+
+	i := slices.IndexFunc(mapping.Values, func(mv *ast.MappingValueNode) bool {
+		return yamlKey(mv.Key) == extendsKey
+	})
+	if i < 0 {
+		return nil
+	}
+	value := mapping.Values[i].Value
+	mapping.Values = slices.Delete(mapping.Values, i, i+1)
+
+	var paths []ast.Node
+	if seq, ok := value.(*ast.SequenceNode); ok {
+		paths = seq.Values
+	} else {
+		paths = []ast.Node{value}
+	}
+
+	for _, p := range paths {
+		files, err := includePaths(p, base)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			if err := c.extendFile(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) extendFile(file includeBase) error {
+	reader, err := file.open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := c.parse(reader); err != nil {
+		return fmt.Errorf("%s: %w", file.name, err)
+	}
+	return nil
+}
+
+// include replaces all nodes of the given node which are tagged with the include tag by the content of the included
+// files. The tokens of the included content are recorded with the name of their file (for the origins).
+func (c *Config) include(node ast.Node, base includeBase, included map[*token.Token]string) (ast.Node, error) {
+	var err error
+
+	switch n := node.(type) {
+	case *ast.TagNode:
+		if n.Start.Value == includeTag {
+			return c.includeFiles(n, base, included)
+		}
+		n.Value, err = c.include(n.Value, base, included)
+	case *ast.AnchorNode:
+		n.Value, err = c.include(n.Value, base, included)
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			if _, err = c.include(value, base, included); err != nil {
+				break
+			}
+		}
+	case *ast.MappingValueNode:
+		n.Value, err = c.include(n.Value, base, included)
+	case *ast.SequenceNode:
+		for i := range n.Values {
+			if n.Values[i], err = c.include(n.Values[i], base, included); err != nil {
+				break
+			}
+		}
+	}
+	return node, err
+}
+
+// includeFiles returns the (merged) content of the files which are named by the given tag node. If a glob pattern
+// matches multiple files, their mappings are merged and their sequences are concatenated.
+func (c *Config) includeFiles(tag *ast.TagNode, base includeBase, included map[*token.Token]string) (ast.Node, error) {
+	files, err := includePaths(tag.Value, base)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ast.Node
+	for _, file := range files {
+		node, err := c.includeFile(file, included)
+		if err != nil {
+			return nil, err
+		}
+		result = mergeNodes(result, node)
+	}
+
+	if result == nil {
+		// no file matches the pattern
+		return ast.Null(token.New("null", "null", tag.GetToken().Position)), nil
+	}
+	return result, nil
+}
+
+func (c *Config) includeFile(file includeBase, included map[*token.Token]string) (ast.Node, error) {
+	leave, err := c.enterInclude(file)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	if format, _ := formatOf(file.name); format == formatToml {
+		return nil, fmt.Errorf("%s: only yaml and json files can be included", file.name)
+	}
+
+	reader, err := file.open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.name, err)
+	}
+
+	parsed, err := parser.ParseBytes(content, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.name, err)
+	}
+
+	var body ast.Node
+	if len(parsed.Docs) > 0 {
+		body = parsed.Docs[0].Body
+	}
+	if body == nil {
+		return nil, nil
+	}
+
+	ast.Walk(tokenVisitor(func(tk *token.Token) {
+		included[tk] = file.name
+	}), body)
+
+	// the included file can include other files (relative to itself)
+	body, err = c.include(body, file, included)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.name, err)
+	}
+	return body, nil
+}
+
+// includePaths returns the files which are named by the given (string) node.
+func includePaths(node ast.Node, base includeBase) ([]includeBase, error) {
+	sn, ok := node.(*ast.StringNode)
+	if !ok || sn.Value == "" {
+		pos := node.GetToken().Position
+		return nil, fmt.Errorf("[%d:%d] expected a path but got %s", pos.Line, pos.Column, node.Type())
+	}
+	return base.files(sn.Value)
+}
+
+// mergeNodes merges the given nodes: the values of mappings are merged recursively, sequences are concatenated and
+// all other nodes are replaced.
+func mergeNodes(a, b ast.Node) ast.Node {
+	switch an := a.(type) {
+	case *ast.MappingNode:
+		bn, ok := b.(*ast.MappingNode)
+		if !ok {
+			return b
+		}
+		for _, bv := range bn.Values {
+			i := slices.IndexFunc(an.Values, func(av *ast.MappingValueNode) bool {
+				return yamlKey(av.Key) == yamlKey(bv.Key)
+			})
+			if i < 0 {
+				an.Values = append(an.Values, bv)
+			} else {
+				an.Values[i].Value = mergeNodes(an.Values[i].Value, bv.Value)
+			}
+		}
+		return an
+	case *ast.SequenceNode:
+		if bn, ok := b.(*ast.SequenceNode); ok {
+			an.Values = append(an.Values, bn.Values...)
+			return an
+		}
+	}
+	if b == nil {
+		return a
+	}
+	return b
+}
+
+// tokenVisitor is an ast.Visitor which calls the function for the token of each node.
+type tokenVisitor func(tk *token.Token)
+
+func (t tokenVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		return nil
+	}
+	if tk := node.GetToken(); tk != nil {
+		t(tk)
+	}
+	return t
+}
+
+// tokenOrigin returns a function which resolves the origin of a token. The tokens of included files are resolved
+// to the included file, all other tokens by the given function.
+func tokenOrigin(resolve func(line int) Origin, included map[*token.Token]string) func(tk *token.Token) Origin {
+	return func(tk *token.Token) Origin {
+		if tk == nil {
+			return resolve(0)
+		}
+		if name, ok := included[tk]; ok {
+			return Origin{Kind: OriginFile, Name: name, Line: tk.Position.Line}
+		}
+		return resolve(tk.Position.Line)
+	}
+}
@@ -0,0 +1,199 @@
+package yacl
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+type includeConfig struct {
+	Name     string `yaml:"name"`
+	Database struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"database"`
+	Servers []string          `yaml:"servers"`
+	Plugins map[string]string `yaml:"plugins"`
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestConfig_Include(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": `
+name: main
+database: !include db/database.yaml
+servers:
+- first
+- !include db/server.yaml
+`,
+		"db/database.yaml": "host: localhost\nport: !include port.yaml\n",
+		"db/port.yaml":     "5432",
+		"db/server.yaml":   "second",
+	})
+
+	c := includeConfig{}
+	toTest := NewConfig(&c, WithIncludes(true))
+
+	assert.NoError(t, toTest.ParseFile(filepath.Join(dir, "config.yaml")))
+	assert.Equal(t, "main", c.Name)
+	assert.Equal(t, "localhost", c.Database.Host)
+	assert.Equal(t, 5432, c.Database.Port)
+	assert.Equal(t, []string{"first", "second"}, c.Servers)
+
+	o, _ := toTest.Origin("database.host")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "db", "database.yaml"), Line: 1}, o)
+	o, _ = toTest.Origin("database.port")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "db", "port.yaml"), Line: 1}, o)
+	o, _ = toTest.Origin("name")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "config.yaml"), Line: 2}, o)
+}
+
+func TestConfig_Include_Glob(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":        "plugins: !include plugins.d/*.yaml\nservers: !include servers/*.yaml\n",
+		"plugins.d/10.yaml":  "auth: basic\nlog: file\n",
+		"plugins.d/20.yaml":  "auth: oauth\n",
+		"servers/a.yaml":     "- a\n",
+		"servers/b.yaml":     "- b\n- c\n",
+		"servers/ignore.yml": "- ignored\n",
+	})
+
+	c := includeConfig{}
+	toTest := NewConfig(&c, WithIncludes(true))
+
+	assert.NoError(t, toTest.ParseFile(filepath.Join(dir, "config.yaml")))
+	assert.Equal(t, map[string]string{"auth": "oauth", "log": "file"}, c.Plugins)
+	assert.Equal(t, []string{"a", "b", "c"}, c.Servers)
+
+	o, _ := toTest.Origin("plugins[auth]")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "plugins.d", "20.yaml"), Line: 1}, o)
+}
+
+func TestConfig_Extends(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": `
+extends:
+- base/base.yaml
+- local.json
+name: main
+`,
+		"base/base.yaml": "extends: defaults.toml\nname: base\ndatabase:\n  host: base\n",
+		"base/defaults.toml": `
+name = "defaults"
+servers = ["default"]
+
+[database]
+port = 5432
+`,
+		"local.json": `{"database": {"host": "local"}}`,
+	})
+
+	c := includeConfig{}
+	toTest := NewConfig(&c, WithIncludes(true), WithStrict(true))
+
+	assert.NoError(t, toTest.ParseFile(filepath.Join(dir, "config.yaml")))
+	assert.Equal(t, "main", c.Name)
+	assert.Equal(t, "local", c.Database.Host)
+	assert.Equal(t, 5432, c.Database.Port)
+	assert.Equal(t, []string{"default"}, c.Servers)
+
+	o, _ := toTest.Origin("database.port")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "base", "defaults.toml")}, o)
+	o, _ = toTest.Origin("database.host")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "local.json"), Line: 1}, o)
+	o, _ = toTest.Origin("name")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "config.yaml"), Line: 5}, o)
+}
+
+func TestConfig_Include_Cycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "extends: b.yaml\n",
+		"b.yaml": "name: !include c.yaml\n",
+		"c.yaml": "!include a.yaml\n",
+	})
+
+	c := includeConfig{}
+	err := NewConfig(&c, WithIncludes(true)).ParseFile(filepath.Join(dir, "a.yaml"))
+	assert.ErrorContains(t, err, "include cycle detected: "+filepath.Join(dir, "a.yaml")+" -> "+
+		filepath.Join(dir, "b.yaml")+" -> "+filepath.Join(dir, "c.yaml")+" -> "+filepath.Join(dir, "a.yaml"))
+}
+
+func TestConfig_Include_Error(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":  "database: !include invalid.yaml\n",
+		"invalid.yaml": "host: [\n",
+		"missing.yaml": "database: !include missing/database.yaml\n",
+		"no-path.yaml": "database: !include\n  host: localhost\n",
+	})
+
+	c := includeConfig{}
+	toTest := NewConfig(&c, WithIncludes(true))
+
+	assert.ErrorContains(t, toTest.ParseFile(filepath.Join(dir, "config.yaml")), filepath.Join(dir, "invalid.yaml")+": [1:7]")
+	assert.ErrorIs(t, toTest.ParseFile(filepath.Join(dir, "missing.yaml")), os.ErrNotExist)
+	assert.ErrorContains(t, toTest.ParseFile(filepath.Join(dir, "no-path.yaml")), "[2:7] expected a path but got Mapping")
+}
+
+func TestConfig_Include_Disabled(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "extends: base.yaml\nname: main\n",
+		"base.yaml":   "database:\n  host: base\n",
+	})
+
+	c := includeConfig{}
+	assert.NoError(t, NewConfig(&c).ParseFile(filepath.Join(dir, "config.yaml")))
+	assert.Equal(t, "main", c.Name)
+	assert.Equal(t, "", c.Database.Host)
+
+	c = includeConfig{}
+	assert.Error(t, NewConfig(&c, WithStrict(true)).ParseFile(filepath.Join(dir, "config.yaml")))
+}
+
+func TestConfig_Include_Unnamed(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"database.yaml": "host: localhost\n",
+	})
+
+	c := includeConfig{}
+	toTest := NewConfig(&c, WithIncludes(true))
+
+	assert.NoError(t, toTest.ParseYaml(bytes.NewBufferString("database: !include "+filepath.Join(dir, "database.yaml"))))
+	assert.Equal(t, "localhost", c.Database.Host)
+
+	// arguments are never interpreted
+	assert.NoError(t, toTest.ParseArguments("--name=!include other.yaml"))
+	assert.Equal(t, "!include other.yaml", c.Name)
+}
+
+func TestConfig_Include_FS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf.d/10-base.yaml":  {Data: []byte("extends: ../shared/base.yaml\nservers: !include ../shared/servers.yaml\n")},
+		"shared/base.yaml":     {Data: []byte("name: base\n")},
+		"shared/servers.yaml":  {Data: []byte("- a\n")},
+		"conf.d/20-local.yaml": {Data: []byte("database: !include /shared/database.yaml\n")},
+		"shared/database.yaml": {Data: []byte("host: shared\n")},
+	}
+
+	c := includeConfig{}
+	toTest := NewConfig(&c, WithIncludes(true))
+
+	assert.NoError(t, toTest.ParseFS(fsys, "conf.d/*.yaml"))
+	assert.Equal(t, "base", c.Name)
+	assert.Equal(t, []string{"a"}, c.Servers)
+	assert.Equal(t, "shared", c.Database.Host)
+
+	o, _ := toTest.Origin("servers[0]")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: "shared/servers.yaml", Line: 1}, o)
+}
@@ -12,6 +12,9 @@ type FieldInfo interface {
 
 	// Field returns the corresponding field in the destination struct.
 	Field() reflect.StructField
+
+	// Secret returns true if the value of the field is secret (see WithSecretTag and Secret).
+	Secret() bool
 }
 
 type FieldInfos interface {
@@ -22,11 +25,17 @@ type FieldInfos interface {
 type fieldInfo struct {
 	path         fieldPath
 	short        string
+	positional   string
+	required     bool
 	defaultValue any
 	sType        string
+	typeHint     string
 	field        reflect.StructField
 }
 
+// positionalRest is the value of the positional tag for the field which takes all remaining positional arguments.
+const positionalRest = "rest"
+
 type fieldPath []*fieldPathNode
 
 type fieldPathNode struct {
@@ -35,11 +44,18 @@ type fieldPathNode struct {
 	mapKeyType reflect.Type
 	isSlice    bool
 	usage      string
+	command    string
+	secret     bool
 }
 
 type fieldInfos struct {
 	fi      []fieldInfo
 	options Options
+
+	// commands contains the subcommands (only used for the help)
+	commands []commandInfo
+	// globals contains the flags of the parent commands (only used for the help)
+	globals *fieldInfos
 }
 
 // CollectInfos returns a list of all fields which are defined in the destination struct.
@@ -52,7 +68,33 @@ func (c *Config) collectInfos() *fieldInfos {
 		options: c.options,
 	}
 
-	c.scan(reflect.TypeOf(c.dest), []*fieldPathNode{}, &infos.fi)
+	c.scan(reflect.TypeOf(c.dest), []*fieldPathNode{}, nil, &infos.fi)
+
+	for i := range infos.fi {
+		infos.fi[i].required = c.isRequired(&infos.fi[i])
+		infos.fi[i].typeHint = infos.fi[i].field.Tag.Get(c.options.typeTag)
+	}
+
+	//ignore short-hand and positional arguments for nodes which are in subcommands
+	for i := range infos.fi {
+		if infos.fi[i].inCommand() {
+			infos.fi[i].short = ""
+			infos.fi[i].positional = ""
+		}
+	}
+
+	//ignore positional arguments for nodes which are in maps or slices
+	for i := range infos.fi {
+		if infos.fi[i].positional == "" {
+			continue
+		}
+		for j, node := range infos.fi[i].path {
+			if (node.isMap || node.isSlice) && j < len(infos.fi[i].path)-1 {
+				infos.fi[i].positional = ""
+				break
+			}
+		}
+	}
 
 	//ignore short-hand for ...
 	for i := range infos.fi {
@@ -86,14 +128,17 @@ func (c *Config) collectInfos() *fieldInfos {
 	return &infos
 }
 
-func (c *Config) scan(t reflect.Type, parent fieldPath, infos *[]fieldInfo) {
+// scan collects the fields of the given struct type. The ancestors are the struct types of the parent path: recursive
+// types are only scanned once per path, otherwise there would be infinitely many fields.
+func (c *Config) scan(t reflect.Type, parent fieldPath, ancestors []reflect.Type, infos *[]fieldInfo) {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
-	if t.Kind() != reflect.Struct {
+	if t.Kind() != reflect.Struct || slices.Contains(ancestors, t) {
 		return
 	}
+	ancestors = append(slices.Clip(ancestors), t)
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
@@ -113,70 +158,114 @@ func (c *Config) scan(t reflect.Type, parent fieldPath, infos *[]fieldInfo) {
 
 		node.key = strings.Split(yamlTag, ",")[0]
 		node.usage = c.getUsage(t, field)
+		node.secret = field.Tag.Get(c.options.secretTag) == "true"
 
 		shortTag := field.Tag.Get(c.options.shortTag)
+		positionalTag := field.Tag.Get(c.options.positionalTag)
+
+		if isSecretType(field.Type) || (field.Type.Kind() == reflect.Ptr && isSecretType(field.Type.Elem())) {
+			node.secret = true
+
+			var sType string
+			if field.Type.Kind() == reflect.Ptr {
+				sType = "*" + secretValueType(field.Type.Elem())
+			} else {
+				sType = secretValueType(field.Type)
+			}
 
+			*infos = append(*infos, fieldInfo{
+				path:       subPath.purge(),
+				short:      shortTag,
+				positional: positionalTag,
+				sType:      sType,
+				field:      field,
+			})
+			continue
+		}
+		if isScalarType(field.Type) {
+			// well-known types (like time.Duration) and custom scalars are represented by a single value
+			fInfo := fieldInfo{
+				path:       subPath.purge(),
+				short:      shortTag,
+				positional: positionalTag,
+				sType:      typeName(field.Type),
+				field:      field,
+			}
+			if field.Type.Kind() == reflect.Ptr {
+				fInfo.sType = "*" + fInfo.sType
+			} else if defValue, ok := c.getDefaultValue(t, field); ok {
+				fInfo.defaultValue = defValue
+			}
+
+			*infos = append(*infos, fInfo)
+			continue
+		}
 		switch field.Type.Kind() {
 		case reflect.Struct:
-			c.scan(field.Type, subPath, infos)
+			node.command = field.Tag.Get(c.options.commandTag)
+			c.scan(field.Type, subPath, ancestors, infos)
 		case reflect.Ptr:
 			if field.Type.Elem().Kind() == reflect.Struct {
-				c.scan(field.Type.Elem(), subPath, infos)
+				node.command = field.Tag.Get(c.options.commandTag)
+				c.scan(field.Type.Elem(), subPath, ancestors, infos)
 			} else {
 				// for pointers to primitives, we just add the fieldInfo
 				info := fieldInfo{
-					path:  subPath.purge(),
-					short: shortTag,
-					sType: "*" + field.Type.Elem().Kind().String(),
-					field: field,
+					path:       subPath.purge(),
+					short:      shortTag,
+					positional: positionalTag,
+					sType:      "*" + field.Type.Elem().Kind().String(),
+					field:      field,
 				}
 				*infos = append(*infos, info)
 			}
 		case reflect.Slice, reflect.Array:
 			node.isSlice = true
-			if field.Type.Elem().Kind() == reflect.Struct ||
-				(field.Type.Elem().Kind() == reflect.Ptr && field.Type.Elem().Elem().Kind() == reflect.Struct) {
+			if !isScalarType(field.Type.Elem()) && (field.Type.Elem().Kind() == reflect.Struct ||
+				(field.Type.Elem().Kind() == reflect.Ptr && field.Type.Elem().Elem().Kind() == reflect.Struct)) {
 				elemType := field.Type.Elem()
 				if elemType.Kind() == reflect.Ptr {
 					elemType = elemType.Elem()
 				}
-				c.scan(elemType, subPath, infos)
+				c.scan(elemType, subPath, ancestors, infos)
 			} else {
 				// for slices of primitives, we just add the fieldInfo
 				info := fieldInfo{
-					path:  subPath.purge(),
-					short: shortTag,
-					sType: "[]" + field.Type.Elem().Kind().String(),
-					field: field,
+					path:       subPath.purge(),
+					short:      shortTag,
+					positional: positionalTag,
+					sType:      "[]" + typeName(field.Type.Elem()),
+					field:      field,
 				}
 				*infos = append(*infos, info)
 			}
 		case reflect.Map:
 			node.isMap = true
 			node.mapKeyType = field.Type.Key()
-			if field.Type.Elem().Kind() == reflect.Struct ||
-				(field.Type.Elem().Kind() == reflect.Ptr && field.Type.Elem().Elem().Kind() == reflect.Struct) {
+			if !isScalarType(field.Type.Elem()) && (field.Type.Elem().Kind() == reflect.Struct ||
+				(field.Type.Elem().Kind() == reflect.Ptr && field.Type.Elem().Elem().Kind() == reflect.Struct)) {
 				elemType := field.Type.Elem()
 				if elemType.Kind() == reflect.Ptr {
 					elemType = elemType.Elem()
 				}
-				c.scan(elemType, subPath, infos)
+				c.scan(elemType, subPath, ancestors, infos)
 			} else {
 				// for maps of primitives, we just add the fieldInfo
 				fInfo := fieldInfo{
 					path:  subPath.purge(),
 					short: shortTag,
-					sType: "map[" + field.Type.Key().Kind().String() + "]" + field.Type.Elem().Kind().String(),
+					sType: "map[" + field.Type.Key().Kind().String() + "]" + typeName(field.Type.Elem()),
 					field: field,
 				}
 				*infos = append(*infos, fInfo)
 			}
 		default:
 			fInfo := fieldInfo{
-				path:  subPath.purge(),
-				short: shortTag,
-				sType: field.Type.Kind().String(),
-				field: field,
+				path:       subPath.purge(),
+				short:      shortTag,
+				positional: positionalTag,
+				sType:      field.Type.Kind().String(),
+				field:      field,
 			}
 			if defValue, ok := c.getDefaultValue(t, field); ok {
 				fInfo.defaultValue = defValue
@@ -235,6 +324,15 @@ func (f *fieldInfos) findByShort(key string) *fieldInfo {
 	return nil
 }
 
+func (f *fieldInfos) findByPositional(positional string) *fieldInfo {
+	for _, info := range f.fi {
+		if info.positional == positional {
+			return &info
+		}
+	}
+	return nil
+}
+
 func (f *fieldInfos) findByPath(path []string) *fieldInfo {
 	joinedPath := strings.Join(path, string(f.options.keyDelimiter))
 	for _, info := range f.fi {
@@ -251,6 +349,110 @@ func (f *fieldInfos) findByPath(path []string) *fieldInfo {
 	return nil
 }
 
+// normalizePath converts the given path segments into the normalized form: map keys and slice indices are
+// enclosed in brackets, all other segments are not. It returns the corresponding fieldInfo (if any).
+func (f *fieldInfos) normalizePath(segments []string) ([]string, *fieldInfo) {
+	raw := rawPath(segments)
+	for i := range f.fi {
+		if path, ok := f.fi[i].match(raw, false); ok {
+			return path, &f.fi[i]
+		}
+	}
+
+	return segments, nil
+}
+
+// known checks if the given path segments are addressing a field (or a parent of a field).
+func (f *fieldInfos) known(segments []string) bool {
+	raw := rawPath(segments)
+	for i := range f.fi {
+		if _, ok := f.fi[i].match(raw, true); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// container returns the node of the map or slice which contains the segment at the given index of the path (the
+// key or the index). If there is no such node, nil is returned.
+func (f *fieldInfos) container(segments []string, i int) *fieldPathNode {
+	raw := rawPath(segments)
+	for _, info := range f.fi {
+		j := 0
+		for _, node := range info.path {
+			if j >= i || raw[j] != node.key {
+				break
+			}
+			j++
+			if node.isMap || node.isSlice {
+				if j == i {
+					return node
+				}
+				j++
+			}
+		}
+	}
+	return nil
+}
+
+// rawPath removes the brackets of the given path segments.
+func rawPath(segments []string) []string {
+	raw := make([]string, len(segments))
+	for i, segment := range segments {
+		raw[i] = strings.TrimSuffix(strings.TrimPrefix(segment, "["), "]")
+	}
+	return raw
+}
+
+// match checks if the given raw segments (without brackets) are addressing this field. If so, it returns the
+// normalized path. If allowPrefix is true, the segments are also matching if they are addressing a parent.
+func (f *fieldInfo) match(raw []string, allowPrefix bool) ([]string, bool) {
+	result := make([]string, 0, len(raw))
+
+	j := 0
+	for _, node := range f.path {
+		if j >= len(raw) {
+			return result, allowPrefix
+		}
+		if raw[j] != node.key {
+			return nil, false
+		}
+		result = append(result, node.key)
+		j++
+
+		if node.isMap || node.isSlice {
+			if j >= len(raw) {
+				// the whole map/slice is addressed
+				return result, true
+			}
+			result = append(result, "["+raw[j]+"]")
+			j++
+		}
+	}
+
+	if j < len(raw) {
+		dynamic := len(f.path) > 0 && f.path[len(f.path)-1].isMap
+		dynamic = dynamic || (f.field.Type != nil && f.field.Type.Kind() == reflect.Interface)
+		if !dynamic {
+			return nil, false
+		}
+
+		// nested keys of dynamic values
+		for ; j < len(raw); j++ {
+			result = append(result, "["+raw[j]+"]")
+		}
+	}
+
+	return result, true
+}
+
+// inCommand checks if the field is a part of a subcommand.
+func (f *fieldInfo) inCommand() bool {
+	return slices.ContainsFunc(f.path, func(node *fieldPathNode) bool {
+		return node.command != ""
+	})
+}
+
 func (f *fieldInfo) Path() string {
 	return f.path.key(newDefaultOptions(), "")
 }
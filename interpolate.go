@@ -0,0 +1,244 @@
+package yacl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// InterpolationError is returned if there are variables which can not be expanded (see WithInterpolation).
+type InterpolationError struct {
+	Fields []FieldError
+}
+
+func (e *InterpolationError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = field.Error()
+	}
+	return "uninterpolatable values: " + strings.Join(fields, ", ")
+}
+
+var reVariableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// interpolate expands the variables inside the given value: "${VAR}" is replaced by the value of the variable
+// (or an empty string), "${VAR:-default}" by the default if the variable is unset or empty and "${VAR:?message}"
+// results in an error if the variable is unset or empty. "$$" is replaced by a single "$".
+func interpolate(value string, lookup func(name string) (string, bool)) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				// the value is not part of the error, because it could be a secret
+				return "", errors.New("unterminated variable reference")
+			}
+
+			expanded, err := expandVariable(value[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(expanded)
+			i = end
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String(), nil
+}
+
+// closingBrace returns the index of the brace which closes the variable reference which starts at the given index.
+// Nested variable references (e.g. inside the default value) are skipped.
+func closingBrace(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch {
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '$':
+			i++
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			depth++
+			i++
+		case value[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// expandVariable expands the content of a variable reference ("VAR", "VAR:-default" or "VAR:?message").
+func expandVariable(reference string, lookup func(name string) (string, bool)) (string, error) {
+	name, operator, argument := reference, "", ""
+	if i := strings.Index(reference, ":"); i >= 0 {
+		name, operator, argument = reference[:i], reference[i:min(i+2, len(reference))], reference[min(i+2, len(reference)):]
+	}
+	if !reVariableName.MatchString(name) || (operator != "" && operator != ":-" && operator != ":?") {
+		return "", errors.New("invalid variable reference")
+	}
+
+	value, _ := lookup(name)
+	if value != "" {
+		return value, nil
+	}
+
+	switch operator {
+	case "":
+		return "", nil
+	case ":-":
+		// the default value can contain variables too
+		return interpolate(argument, lookup)
+	default: // ":?"
+		message, err := interpolate(argument, lookup)
+		if err != nil {
+			return "", err
+		}
+		if message == "" {
+			return "", fmt.Errorf("variable %s is not set", name)
+		}
+		return "", fmt.Errorf("variable %s is not set: %s", name, message)
+	}
+}
+
+// interpolateBody expands the variables inside all string values of the given body. Values of arguments and
+// environment variables are treated as unquoted values, so they can be expanded to numbers or booleans. The
+// replaced tokens of included files are recorded as well.
+func (c *Config) interpolateBody(body *ast.Node, reader any, included map[*token.Token]string) error {
+	_, isReader := reader.(*Reader)
+	infos := c.collectInfos()
+
+	i := &interpolation{
+		lookup:   c.options.lookupEnv,
+		unquoted: isReader,
+		included: included,
+	}
+	i.node(body, nil)
+
+	if len(i.failures) == 0 {
+		return nil
+	}
+
+	fields := make([]FieldError, len(i.failures))
+	for idx, failure := range i.failures {
+		path, _ := infos.normalizePath(failure.segments)
+		fields[idx] = FieldError{Flag: c.flagOf(path), Err: failure.err}
+	}
+	return &InterpolationError{Fields: fields}
+}
+
+type interpolation struct {
+	lookup   func(name string) (string, bool)
+	unquoted bool
+	included map[*token.Token]string
+
+	failures []interpolationFailure
+}
+
+// interpolationFailure is a value whose variables can not be expanded.
+type interpolationFailure struct {
+	segments []string
+	err      error
+}
+
+func (i *interpolation) node(node *ast.Node, segments []string) {
+	switch n := (*node).(type) {
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			i.node(&value.Value, append(slices.Clip(segments), yamlKey(value.Key)))
+		}
+	case *ast.MappingValueNode:
+		i.node(&n.Value, append(slices.Clip(segments), yamlKey(n.Key)))
+	case *ast.SequenceNode:
+		for idx := range n.Values {
+			i.node(&n.Values[idx], append(slices.Clip(segments), fmt.Sprintf("[%d]", idx)))
+		}
+	case *ast.TagNode:
+		i.node(&n.Value, segments)
+	case *ast.AnchorNode:
+		i.node(&n.Value, segments)
+	case *ast.LiteralNode:
+		if expanded, ok := i.expand(n.Value.Value, segments); ok {
+			n.Value.Value = expanded
+		}
+	case *ast.StringNode:
+		expanded, ok := i.expand(n.Value, segments)
+		if !ok || expanded == n.Value {
+			return
+		}
+
+		*node = interpolatedNode(n, expanded, i.unquoted || n.Token.Type == token.StringType)
+		if name, isIncluded := i.included[n.Token]; isIncluded {
+			i.included[(*node).GetToken()] = name
+		}
+	}
+}
+
+func (i *interpolation) expand(value string, segments []string) (string, bool) {
+	expanded, err := interpolate(value, i.lookup)
+	if err != nil {
+		i.failures = append(i.failures, interpolationFailure{segments: segments, err: err})
+		return "", false
+	}
+	return expanded, true
+}
+
+// interpolatedNode returns the node which replaces the given string node after its value is expanded. If the
+// original value was unquoted, the expanded value is interpreted like an unquoted value (e.g. as number).
+func interpolatedNode(n *ast.StringNode, expanded string, unquoted bool) ast.Node {
+	pos := *n.Token.Position
+
+	if unquoted {
+		file, err := parser.ParseBytes([]byte(expanded), 0)
+		if err == nil && len(file.Docs) > 0 {
+			switch body := file.Docs[0].Body.(type) {
+			case *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode, *ast.NullNode, *ast.InfinityNode, *ast.NanNode:
+				if tk := body.GetToken(); tk.Value == expanded {
+					tk.Position = &pos
+					return body
+				}
+			}
+		}
+	}
+
+	tk := *n.Token
+	tk.Value = expanded
+	tk.Position = &pos
+
+	replacement := *n
+	replacement.Token = &tk
+	replacement.Value = expanded
+	return &replacement
+}
+
+// envLookup returns a function which looks up the variables inside the given environment ("NAME=value").
+func envLookup(env []string) func(name string) (string, bool) {
+	values := map[string]string{}
+	for _, e := range env {
+		if name, value, ok := strings.Cut(e, "="); ok {
+			values[name] = value
+		}
+	}
+	return func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
@@ -0,0 +1,137 @@
+package yacl
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	lookup := envLookup([]string{"HOST=db", "PORT=5432", "EMPTY=", "NESTED=${HOST}"})
+
+	tests := []struct {
+		value    string
+		expected string
+		err      string
+	}{
+		{value: "plain", expected: "plain"},
+		{value: "${HOST}:${PORT}", expected: "db:5432"},
+		{value: "${MISSING}", expected: ""},
+		{value: "${MISSING:-localhost}", expected: "localhost"},
+		{value: "${EMPTY:-localhost}", expected: "localhost"},
+		{value: "${HOST:-localhost}", expected: "db"},
+		{value: "${MISSING:-${HOST}:${PORT}}", expected: "db:5432"},
+		{value: "${MISSING:-{}}", expected: "{}"},
+		{value: "${NESTED}", expected: "${HOST}"},
+		{value: "$$HOST and $${HOST}", expected: "$HOST and ${HOST}"},
+		{value: "costs 5$ or $HOST$", expected: "costs 5$ or $HOST$"},
+		{value: "${HOST:?must be set}", expected: "db"},
+		{value: "${MISSING:?}", err: "variable MISSING is not set"},
+		{value: "${EMPTY:?must be set}", err: "variable EMPTY is not set: must be set"},
+		{value: "${HOST", err: `unterminated variable reference`},
+		{value: "${1HOST}", err: `invalid variable reference`},
+		{value: "${HOST:+alternative}", err: `invalid variable reference`},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result, err := interpolate(test.value, lookup)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, result)
+			}
+		})
+	}
+}
+
+type interpolationConfig struct {
+	URL      string   `yaml:"url"`
+	Port     int      `yaml:"port"`
+	Debug    bool     `yaml:"debug"`
+	Name     string   `yaml:"name"`
+	Script   string   `yaml:"script"`
+	Hosts    []string `yaml:"hosts"`
+	Database struct {
+		Password string `yaml:"password"`
+	} `yaml:"database"`
+}
+
+func TestConfig_Interpolation_ParseYaml(t *testing.T) {
+	c := interpolationConfig{}
+	toTest := NewConfig(&c, WithInterpolationEnv("DB_PORT=5432", "DEBUG=true", "NAME=007", "USER=app"))
+
+	assert.NoError(t, toTest.ParseYaml(bytes.NewBufferString(`
+url: postgres://${DB_HOST:-localhost}:${DB_PORT}/app
+port: ${DB_PORT}
+debug: ${DEBUG}
+name: "${NAME}"
+script: |
+  echo ${USER}
+  echo $$HOME
+hosts:
+- ${DB_HOST:-localhost}
+- backup
+`)))
+	assert.Equal(t, "postgres://localhost:5432/app", c.URL)
+	assert.Equal(t, 5432, c.Port)
+	assert.True(t, c.Debug)
+	assert.Equal(t, "007", c.Name)
+	assert.Equal(t, "echo app\necho $HOME\n", c.Script)
+	assert.Equal(t, []string{"localhost", "backup"}, c.Hosts)
+
+	o, _ := toTest.Origin("port")
+	assert.Equal(t, Origin{Kind: OriginFile, Line: 3}, o)
+}
+
+func TestConfig_Interpolation_ArgumentsAndEnvironment(t *testing.T) {
+	c := interpolationConfig{}
+	toTest := NewConfig(&c, WithInterpolationEnv("DB_PORT=5432", "DEBUG=true", "SECRET=pa$$word"))
+
+	assert.NoError(t, toTest.ParseArguments("--port=${DB_PORT}", "--debug=${DEBUG}", "--name=${MISSING:-default}"))
+	assert.Equal(t, 5432, c.Port)
+	assert.True(t, c.Debug)
+	assert.Equal(t, "default", c.Name)
+
+	assert.NoError(t, toTest.ParseEnvironment("CFG_0=--database.password=${SECRET}", "CFG_1=--url=$${DB_PORT}"))
+	assert.Equal(t, "pa$$word", c.Database.Password)
+	assert.Equal(t, "${DB_PORT}", c.URL)
+}
+
+func TestConfig_Interpolation_Error(t *testing.T) {
+	c := interpolationConfig{}
+	toTest := NewConfig(&c, WithInterpolationEnv())
+
+	err := toTest.ParseYaml(bytes.NewBufferString(`
+url: ${URL:?the url of the database}
+hosts:
+- first
+- ${HOST:?}
+database:
+  password: ${PASSWORD
+`))
+	assert.EqualError(t, err, `uninterpolatable values: --url variable URL is not set: the url of the database, `+
+		`--hosts[1] variable HOST is not set, --database.password unterminated variable reference`)
+	assert.NotContains(t, err.Error(), "PASSWORD")
+
+	var interpolationErr *InterpolationError
+	assert.True(t, errors.As(err, &interpolationErr))
+	assert.Len(t, interpolationErr.Fields, 3)
+	assert.Equal(t, "--url", interpolationErr.Fields[0].Flag)
+
+	err = toTest.ParseArguments("--name=${NAME:?}")
+	assert.EqualError(t, err, `uninterpolatable values: --name variable NAME is not set`)
+}
+
+func TestConfig_Interpolation_Disabled(t *testing.T) {
+	t.Setenv("DB_PORT", "5432")
+
+	c := interpolationConfig{}
+	assert.NoError(t, NewConfig(&c).ParseArguments("--url=${DB_PORT}"))
+	assert.Equal(t, "${DB_PORT}", c.URL)
+
+	c = interpolationConfig{}
+	assert.NoError(t, NewConfig(&c, WithInterpolation(true)).ParseArguments("--url=${DB_PORT}"))
+	assert.Equal(t, "5432", c.URL)
+}
@@ -2,7 +2,10 @@ package yacl
 
 import (
 	"github.com/goccy/go-yaml"
+	"os"
+	"path/filepath"
 	"reflect"
+	"time"
 )
 
 type Options struct {
@@ -13,15 +16,48 @@ type Options struct {
 	prefixShort string
 	prefixEnv   string
 
-	usageTag string
-	shortTag string
+	envMode      EnvMode
+	envSeparator string
+	envCase      EnvCase
+	envTag       string
+
+	usageTag      string
+	shortTag      string
+	positionalTag string
+	commandTag    string
+	validateTag   string
+	requiredTag   string
+	secretTag     string
+	typeTag       string
 
 	defaultSetter     map[reflect.Type]func(any)
 	autoApplyDefaults bool
+	autoValidate      bool
 
 	usageProvider map[reflect.Type]func(any, string) string
 
+	resolvers map[string]Resolver
+
+	watchInterval time.Duration
+
+	programName       string
+	completeTag       string
+	dynamicCompletion bool
+
 	decodeOptions []yaml.DecodeOption
+
+	required []string
+
+	strict bool
+
+	includes bool
+
+	profiles       bool
+	profileKey     string
+	activeProfiles []string
+
+	// lookupEnv looks up the variables for the interpolation (nil if the interpolation is disabled)
+	lookupEnv func(name string) (string, bool)
 }
 
 func newDefaultOptions() Options {
@@ -32,9 +68,24 @@ func newDefaultOptions() Options {
 	WithPrefixLong("--")(&opts)
 	WithPrefixShort("-")(&opts)
 	WithPrefixEnv("CFG_")(&opts)
+	WithEnvMode(EnvModeArguments)(&opts)
+	WithEnvSeparator("_")(&opts)
+	WithEnvCase(EnvCaseUpper)(&opts)
+	WithEnvTag("env")(&opts)
 	WithUsageTag("usage")(&opts)
 	WithShortTag("short")(&opts)
+	WithPositionalTag("positional")(&opts)
+	WithCommandTag("command")(&opts)
+	WithValidateTag("validate")(&opts)
+	WithRequiredTag("required")(&opts)
+	WithSecretTag("secret")(&opts)
+	WithTypeTag("type")(&opts)
 	WithAutoApplyDefaults(true)(&opts)
+	WithAutoValidate(true)(&opts)
+	WithWatchInterval(time.Second)(&opts)
+	WithProgramName(filepath.Base(os.Args[0]))(&opts)
+	WithCompleteTag("complete")(&opts)
+	WithProfileKey("profile")(&opts)
 
 	return opts
 }
@@ -76,6 +127,37 @@ func WithPrefixEnv(prefix string) Option {
 	}
 }
 
+// WithEnvMode sets how the environment variables are interpreted. Default is EnvModeArguments.
+func WithEnvMode(mode EnvMode) Option {
+	return func(o *Options) {
+		o.envMode = mode
+	}
+}
+
+// WithEnvSeparator sets the separator of the parts of the derived environment variable names (see EnvModeNamed).
+// Default is "_".
+func WithEnvSeparator(separator string) Option {
+	return func(o *Options) {
+		o.envSeparator = separator
+	}
+}
+
+// WithEnvCase sets the case of the derived environment variable names (see EnvModeNamed). In case of EnvCaseUpper
+// the map keys inside the names are converted to lower case. Default is EnvCaseUpper.
+func WithEnvCase(envCase EnvCase) Option {
+	return func(o *Options) {
+		o.envCase = envCase
+	}
+}
+
+// WithEnvTag sets the tag for the name of the environment variable of a field (see EnvModeNamed). The name
+// is used as it is (without the env prefix). Default is "env".
+func WithEnvTag(tag string) Option {
+	return func(o *Options) {
+		o.envTag = tag
+	}
+}
+
 // WithUsageTag sets the tag for usage. Default is "usage".
 func WithUsageTag(tag string) Option {
 	return func(o *Options) {
@@ -90,6 +172,61 @@ func WithShortTag(tag string) Option {
 	}
 }
 
+// WithPositionalTag sets the tag for positional arguments. Default is "positional".
+// The value of the tag is the index of the positional argument (e.g. "0") or "rest" for all remaining ones.
+func WithPositionalTag(tag string) Option {
+	return func(o *Options) {
+		o.positionalTag = tag
+	}
+}
+
+// WithCommandTag sets the tag for subcommands. Default is "command".
+// The value of the tag is the name of the subcommand (e.g. "serve") which is bound to the (struct) field.
+func WithCommandTag(tag string) Option {
+	return func(o *Options) {
+		o.commandTag = tag
+	}
+}
+
+// WithValidateTag sets the tag for validation rules (see Config.Validate). Default is "validate".
+func WithValidateTag(tag string) Option {
+	return func(o *Options) {
+		o.validateTag = tag
+	}
+}
+
+// WithRequiredTag sets the tag for required fields (see Config.CheckRequired). Default is "required".
+// The value of the tag must be "true" to mark the field as required.
+func WithRequiredTag(tag string) Option {
+	return func(o *Options) {
+		o.requiredTag = tag
+	}
+}
+
+// WithSecretTag sets the tag for secret fields (see FieldInfo.Secret). Default is "secret".
+// The value of the tag must be "true" to mark the field as secret.
+func WithSecretTag(tag string) Option {
+	return func(o *Options) {
+		o.secretTag = tag
+	}
+}
+
+// WithTypeTag sets the tag for the type name which is shown in the help instead of the real type (e.g. `type:"level"`).
+// Default is "type".
+func WithTypeTag(tag string) Option {
+	return func(o *Options) {
+		o.typeTag = tag
+	}
+}
+
+// WithRequired marks the fields behind the given paths as required (see Config.CheckRequired).
+// The paths can be given in the same syntax as the arguments (e.g. "--server.port", "array[0].key", "array.key").
+func WithRequired(paths ...string) Option {
+	return func(o *Options) {
+		o.required = append(o.required, paths...)
+	}
+}
+
 // WithDecoderOptions sets the decoder options for the parser.
 func WithDecoderOptions(options ...yaml.DecodeOption) Option {
 	return func(o *Options) {
@@ -97,6 +234,87 @@ func WithDecoderOptions(options ...yaml.DecodeOption) Option {
 	}
 }
 
+// WithStrict defines if unknown keys should be rejected. If enabled, each argument, environment variable and yaml key
+// which can not be assigned to a field of the destination struct will result in an UnknownKeyError. Default is false.
+func WithStrict(b bool) Option {
+	return func(o *Options) {
+		o.strict = b
+	}
+}
+
+// WithIncludes defines if yaml files can include other files. If enabled, a value which is tagged with "!include"
+// (e.g. "database: !include database.yaml") is replaced by the content of the given file and the files which are
+// named by the top-level key "extends" (a path or a list of paths) are parsed before the file, so the file overrides
+// their values. The paths are relative to the including file and can be glob patterns (the matching files are
+// merged in lexical order). The origins of the values refer to the included files. Default is false.
+func WithIncludes(b bool) Option {
+	return func(o *Options) {
+		o.includes = b
+	}
+}
+
+// WithProfiles defines if profiles (e.g. "dev", "staging" or "prod") are supported. The active profiles are selected
+// by the flag "--profile=prod,local" or the environment variable "CFG_PROFILE=prod,local" (see WithProfileKey and
+// Config.Load) or by WithActiveProfiles. For each loaded file (e.g. "config.yaml") the overlay files of the active
+// profiles ("config.prod.yaml", "config.local.yaml") are loaded afterward - if they exist. Additionally, the
+// top-level section "profiles" of a file can contain the values of each profile:
+//
+//	url: http://localhost
+//	profiles:
+//	  prod:
+//	    url: https://example.com
+//
+// The values of the active profiles override the values of the file in order of the active profiles. The profile
+// of a value is recorded in its origin (see Config.Origin). Default is false.
+func WithProfiles(b bool) Option {
+	return func(o *Options) {
+		o.profiles = b
+	}
+}
+
+// WithProfileKey sets the key of the flag and the environment variable which select the active profiles (see
+// WithProfiles). Default is "profile" ("--profile" and "CFG_PROFILE").
+func WithProfileKey(key string) Option {
+	return func(o *Options) {
+		o.profileKey = key
+	}
+}
+
+// WithActiveProfiles enables the profiles (see WithProfiles) and sets the profiles which are active if no profile
+// is selected by the sources.
+func WithActiveProfiles(profiles ...string) Option {
+	return func(o *Options) {
+		o.profiles = true
+		o.activeProfiles = profiles
+	}
+}
+
+// WithInterpolation defines if variables inside the values of all sources (yaml, JSON, TOML, arguments and
+// environment variables) should be expanded by the environment variables (os.LookupEnv):
+//
+//	${VAR}            the value of VAR (an empty string if it is not set)
+//	${VAR:-default}   the value of VAR or the default if VAR is unset or empty
+//	${VAR:?message}   the value of VAR or an InterpolationError if VAR is unset or empty
+//	$$                a literal "$"
+//
+// Unquoted values are interpreted after expanding (so "port: ${PORT}" can be decoded as number). Default is false.
+func WithInterpolation(b bool) Option {
+	return func(o *Options) {
+		o.lookupEnv = nil
+		if b {
+			o.lookupEnv = os.LookupEnv
+		}
+	}
+}
+
+// WithInterpolationEnv enables the interpolation (see WithInterpolation) with the given environment variables
+// ("NAME=value") instead of the environment of the process.
+func WithInterpolationEnv(env ...string) Option {
+	return func(o *Options) {
+		o.lookupEnv = envLookup(env)
+	}
+}
+
 // WithAutoApplyDefaults define if the default values should be applied automatically before first parsing. Default is true.
 func WithAutoApplyDefaults(b bool) Option {
 	return func(o *Options) {
@@ -104,6 +322,15 @@ func WithAutoApplyDefaults(b bool) Option {
 	}
 }
 
+// WithAutoValidate define if the values should be validated automatically after parsing (see Config.Validate).
+// The validation is done by ParseArguments and Load (after the default values are applied). The parse functions of
+// files (like ParseYaml) only validate the values which are set by the file. Default is true.
+func WithAutoValidate(b bool) Option {
+	return func(o *Options) {
+		o.autoValidate = b
+	}
+}
+
 // WithDefaults register a function which is responsible for setting default values for the given type.
 func WithDefaults[T any](defaultSetter func(*T)) Option {
 	return func(o *Options) {
@@ -116,7 +343,7 @@ func WithDefaults[T any](defaultSetter func(*T)) Option {
 
 		o.decodeOptions = append(o.decodeOptions, yaml.CustomUnmarshaler(func(dst *T, bytes []byte) error {
 			defaultSetter(dst)
-			return yaml.Unmarshal(bytes, dst)
+			return yaml.UnmarshalWithOptions(bytes, dst, scalarDecodeOptions()...)
 		}))
 	}
 }
@@ -132,3 +359,60 @@ func WithUsage[T any](getUsage func(*T, string) string) Option {
 		}
 	}
 }
+
+// WithDefaultResolvers registers the schemes "file" (see FileResolver) and "env" (see EnvResolver). Note that
+// references can be given by any source, so the values of arguments and environment variables can read any file
+// or environment variable which is accessible by the process.
+func WithDefaultResolvers() Option {
+	return func(o *Options) {
+		WithResolver("file", FileResolver())(o)
+		WithResolver("env", EnvResolver())(o)
+	}
+}
+
+// WithResolver registers the given Resolver for the given scheme (see Config.Resolve). A nil Resolver removes the
+// scheme. By default, no scheme is registered, so all values are taken as they are (see WithDefaultResolvers).
+func WithResolver(scheme string, resolver Resolver) Option {
+	return func(o *Options) {
+		if o.resolvers == nil {
+			o.resolvers = make(map[string]Resolver)
+		}
+		if resolver == nil {
+			delete(o.resolvers, scheme)
+			return
+		}
+		o.resolvers[scheme] = resolver
+	}
+}
+
+// WithWatchInterval sets the interval in which the watched file is checked for changes (see Watch).
+// Default is one second.
+func WithWatchInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.watchInterval = interval
+	}
+}
+
+// WithProgramName sets the name of the program (used by the completion scripts, see Config.CompletionScript).
+// Default is the base name of os.Args[0].
+func WithProgramName(name string) Option {
+	return func(o *Options) {
+		o.programName = name
+	}
+}
+
+// WithCompleteTag sets the tag for the completion of values (see Config.CompletionScript). The value of the tag
+// can be "file" or "dir". Default is "complete".
+func WithCompleteTag(tag string) Option {
+	return func(o *Options) {
+		o.completeTag = tag
+	}
+}
+
+// WithDynamicCompletion enables the dynamic completion: the completion scripts will call the program with the
+// hidden argument CompleteArg to get further candidates (see Config.HandleCompletion). Default is false.
+func WithDynamicCompletion(enabled bool) Option {
+	return func(o *Options) {
+		o.dynamicCompletion = enabled
+	}
+}
@@ -0,0 +1,54 @@
+package yacl
+
+type DiscoveryOptions struct {
+	searchPaths []string
+	pathFlag    string
+	args        []string
+	pathEnv     string
+	mergeAll    bool
+}
+
+type DiscoveryOption func(*DiscoveryOptions)
+
+// WithSearchPaths sets the paths of the configuration files which are searched (in order of their priority). The
+// paths can contain environment variables (e.g. "$XDG_CONFIG_HOME/app/config.yaml") and a leading "~" for the home
+// directory. By default, the following paths are searched for an application with the name "app":
+//
+//	./app.yaml
+//	$XDG_CONFIG_HOME/app/config.yaml (default: ~/.config/app/config.yaml)
+//	~/.app.yaml
+//	$XDG_CONFIG_DIRS/app/config.yaml (default: /etc/xdg/app/config.yaml)
+//	/etc/app/config.yaml
+func WithSearchPaths(paths ...string) DiscoveryOption {
+	return func(o *DiscoveryOptions) {
+		o.searchPaths = paths
+	}
+}
+
+// WithPathFlag sets the flag (e.g. "--config") which overrides the search: if the given arguments contain the flag
+// ("--config=path" or "--config path"), only the given file is loaded. The flag (and its value) is ignored by the
+// arguments which are parsed by the same Config (see Config.Load), so it is no unknown argument in strict mode. The
+// arguments are interpreted by the options of that Config (see WithPrefixLong and WithAssignSign).
+func WithPathFlag(flag string, args ...string) DiscoveryOption {
+	return func(o *DiscoveryOptions) {
+		o.pathFlag = flag
+		o.args = args
+	}
+}
+
+// WithPathEnv sets the name of the environment variable (e.g. "APP_CONFIG") which overrides the search: if the
+// variable is set, only the given file is loaded. The flag (see WithPathFlag) has a higher priority.
+func WithPathEnv(name string) DiscoveryOption {
+	return func(o *DiscoveryOptions) {
+		o.pathEnv = name
+	}
+}
+
+// WithMergeAll defines if all found files should be loaded. The files are applied in reverse order of the search
+// paths, so the values of the first found file have the highest priority. Default is false: only the first found
+// file is loaded.
+func WithMergeAll(b bool) DiscoveryOption {
+	return func(o *DiscoveryOptions) {
+		o.mergeAll = b
+	}
+}
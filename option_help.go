@@ -6,8 +6,9 @@ import (
 )
 
 type HelpOptions struct {
-	sorter Sorter
-	filter Filter
+	sorter  Sorter
+	filter  Filter
+	command []string
 }
 
 func newDefaultHelpOptions() HelpOptions {
@@ -35,6 +36,27 @@ func WithFilter(filter Filter) HelpOption {
 	}
 }
 
+// WithCommand sets the path of the subcommand (e.g. "remote", "add") whose help should be generated. By default,
+// the help of the selected subcommand is generated. Use WithCommand() for the help of the root command.
+func WithCommand(path ...string) HelpOption {
+	return func(o *HelpOptions) {
+		o.command = path
+		if o.command == nil {
+			o.command = []string{}
+		}
+	}
+}
+
+// apply sorts and filters the given infos.
+func (o HelpOptions) apply(infos *fieldInfos) {
+	if o.sorter != nil {
+		infos.Sort(o.sorter)
+	}
+	if o.filter != nil {
+		infos.Filter(o.filter)
+	}
+}
+
 type Sorter func(a, b FieldInfo) int
 
 func (f *fieldInfos) Sort(sorter Sorter) FieldInfos {
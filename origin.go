@@ -0,0 +1,259 @@
+package yacl
+
+import (
+	"fmt"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// OriginKind describes the kind of source a value came from.
+type OriginKind string
+
+const (
+	OriginFile        OriginKind = "file"
+	OriginMap         OriginKind = "map"
+	OriginEnvironment OriginKind = "env"
+	OriginArgument    OriginKind = "argument"
+	OriginDefault     OriginKind = "default"
+)
+
+// Origin describes where a value of the destination struct came from.
+type Origin struct {
+	Kind OriginKind
+
+	// Name is the name of the file (OriginFile), the map (OriginMap) or the environment variable (OriginEnvironment).
+	Name string
+
+	// Line is the line inside the file (OriginFile).
+	Line int
+
+	// Index is the index of the argument inside the parsed arguments (OriginArgument).
+	Index int
+
+	// Profile is the profile which the value belongs to (see WithProfiles). It is empty for values which do not
+	// belong to a profile.
+	Profile string
+}
+
+func (o Origin) String() string {
+	if o.Profile != "" {
+		profileless := o
+		profileless.Profile = ""
+		return fmt.Sprintf("%s (profile %s)", profileless, o.Profile)
+	}
+
+	switch o.Kind {
+	case OriginFile:
+		name := o.Name
+		if name == "" {
+			name = "yaml"
+		}
+		if o.Line > 0 {
+			return fmt.Sprintf("%s:%d", name, o.Line)
+		}
+		return name
+	case OriginMap:
+		return fmt.Sprintf("map %s", o.Name)
+	case OriginEnvironment:
+		return fmt.Sprintf("env %s", o.Name)
+	case OriginArgument:
+		return fmt.Sprintf("argument #%d", o.Index)
+	default:
+		return string(o.Kind)
+	}
+}
+
+// Origin returns the origin of the value behind the given path. The path can be given in the same syntax as
+// the arguments (e.g. "--server.port", "map[key].value", "array.[0].value").
+func (c *Config) Origin(path string) (Origin, bool) {
+	o, ok := c.origins[c.normalizeKey(path)]
+	return o, ok
+}
+
+// Origins returns the origins of all values which were set by any source or by default values. The keys are the
+// paths to the values (e.g. "server.port", "map.[key].value", "array.[0].value").
+func (c *Config) Origins() map[string]Origin {
+	return maps.Clone(c.origins)
+}
+
+// normalizeKey converts the given key (in argument syntax) into the normalized path.
+func (c *Config) normalizeKey(key string) string {
+	path, _ := c.collectInfos().normalizePath(c.splitKey(strings.TrimPrefix(key, c.options.prefixLong)))
+	return c.joinPath(path)
+}
+
+// splitKey splits the given key (in argument syntax) into its segments (e.g. "map[key].value" -> [map [key] value]).
+func (c *Config) splitKey(key string) []string {
+	// replace "array[0]" -> "array.[0]", "map[key].value" -> "map.[key].value"
+	key = indexAbbreviationRegex.ReplaceAllString(key, fmt.Sprintf("$1%c[", c.options.keyDelimiter))
+
+	return splitPreservingBrackets(key, c.options.keyDelimiter)
+}
+
+func (c *Config) joinPath(path []string) string {
+	return strings.Join(path, string(c.options.keyDelimiter))
+}
+
+// originResolver returns a function which resolves the origin of a line of the given reader.
+func originResolver(reader any) func(line int) Origin {
+	switch r := reader.(type) {
+	case *Reader:
+		return func(line int) Origin {
+			return r.origin(r.lineArgs[line])
+		}
+	case *namedReader:
+		if r.kind != "" && r.kind != OriginFile {
+			return func(int) Origin {
+				return Origin{Kind: r.kind, Name: r.name}
+			}
+		}
+	}
+
+	name := ""
+	if named, ok := reader.(interface{ Name() string }); ok {
+		name = named.Name()
+	}
+	return func(line int) Origin {
+		return Origin{Kind: OriginFile, Name: name, Line: line}
+	}
+}
+
+// recordOrigins records the origins of all values inside the given yaml node. All other values which are changed
+// in comparison to the given values (before decoding) are set by DefaultSetters while decoding.
+func (c *Config) recordOrigins(node ast.Node, origin func(tk *token.Token) Origin, before map[string]any) {
+	infos := c.collectInfos()
+	origins := map[string]Origin{}
+	var containers []string
+
+	walkYaml(node, nil, nil, func(segments []string, tk *token.Token) {
+		path, info := infos.normalizePath(segments)
+		origins[c.joinPath(path)] = origin(tk)
+
+		if info != nil {
+			// maps and slices are replaced as whole - so the origins of the old content are not valid anymore
+			for i, pn := range info.path {
+				if pn.isMap || pn.isSlice {
+					containers = append(containers, c.joinPath(path[:c.containerLen(info.path[:i+1])]))
+					break
+				}
+			}
+		}
+	})
+
+	if c.origins == nil {
+		c.origins = map[string]Origin{}
+	}
+	for _, container := range containers {
+		maps.DeleteFunc(c.origins, func(path string, _ Origin) bool {
+			return path == container || strings.HasPrefix(path, container+string(c.options.keyDelimiter))
+		})
+	}
+	maps.Copy(c.origins, origins)
+
+	for _, path := range changedPaths(before, c.values()) {
+		if _, ok := origins[path]; !ok {
+			c.origins[path] = Origin{Kind: OriginDefault}
+		}
+	}
+}
+
+// containerLen returns the number of segments which are necessary to address the last node of the given path.
+func (c *Config) containerLen(path fieldPath) int {
+	l := len(path)
+	for _, pn := range path[:len(path)-1] {
+		if pn.isMap || pn.isSlice {
+			// the key/index segment
+			l++
+		}
+	}
+	return l
+}
+
+// recordDefaultOrigins records the origin for all values which are changed by the given function.
+func (c *Config) recordDefaultOrigins(apply func()) {
+	before := c.values()
+	apply()
+
+	if c.origins == nil {
+		c.origins = map[string]Origin{}
+	}
+	for _, path := range changedPaths(before, c.values()) {
+		c.origins[path] = Origin{Kind: OriginDefault}
+	}
+}
+
+// changedPaths returns all paths whose values are changed to a non-zero value.
+func changedPaths(before, after map[string]any) []string {
+	var result []string
+	for path, value := range after {
+		if value == nil || reflect.ValueOf(value).IsZero() {
+			continue
+		}
+		if old, ok := before[path]; ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		result = append(result, path)
+	}
+	return result
+}
+
+// values returns all (leaf) values of the destination struct.
+func (c *Config) values() map[string]any {
+	result := map[string]any{}
+	c.walkValues(reflect.ValueOf(c.dest), nil, func(path []string, v reflect.Value) {
+		result[c.joinPath(path)] = v.Interface()
+	})
+	return result
+}
+
+// walkYaml walks through all leaf nodes of the given yaml node and calls fn with their paths and tokens (which
+// contain the lines).
+func walkYaml(node ast.Node, path []string, tk *token.Token, fn func(path []string, tk *token.Token)) {
+	if node != nil && node.GetToken() != nil {
+		tk = node.GetToken()
+	}
+
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		if len(n.Values) == 0 && len(path) > 0 {
+			fn(path, tk)
+		}
+		for _, value := range n.Values {
+			walkYaml(value, path, tk, fn)
+		}
+	case *ast.MappingValueNode:
+		keyToken := tk
+		if n.Key.GetToken() != nil {
+			keyToken = n.Key.GetToken()
+		}
+		walkYaml(n.Value, append(slices.Clip(path), yamlKey(n.Key)), keyToken, fn)
+	case *ast.SequenceNode:
+		if len(n.Values) == 0 && len(path) > 0 {
+			fn(path, tk)
+		}
+		for i, value := range n.Values {
+			walkYaml(value, append(slices.Clip(path), fmt.Sprintf("[%d]", i)), tk, fn)
+		}
+	case *ast.TagNode:
+		walkYaml(n.Value, path, tk, fn)
+	case *ast.AnchorNode:
+		walkYaml(n.Value, path, tk, fn)
+	case *ast.CommentGroupNode:
+		// ignore comments
+	default:
+		if len(path) > 0 {
+			fn(path, tk)
+		}
+	}
+}
+
+func yamlKey(key ast.MapKeyNode) string {
+	if sn, ok := key.(*ast.StringNode); ok {
+		return sn.Value
+	}
+	return key.GetToken().Value
+}
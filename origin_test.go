@@ -0,0 +1,135 @@
+package yacl
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Origin_Yaml(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+string: hello
+array:
+  - key: name0
+  - key: name1
+map:
+  test:
+    key: name
+raw-map:
+  deep:
+    key: value
+`)
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	c := testConfig{}
+	toTest := NewConfig(&c)
+	assert.NoError(t, toTest.ParseYaml(f))
+
+	assert.Equal(t, map[string]Origin{
+		"string":               {Kind: OriginFile, Name: path, Line: 2},
+		"array.[0].key":        {Kind: OriginFile, Name: path, Line: 4},
+		"array.[1].key":        {Kind: OriginFile, Name: path, Line: 5},
+		"map.[test].key":       {Kind: OriginFile, Name: path, Line: 8},
+		"raw-map.[deep].[key]": {Kind: OriginFile, Name: path, Line: 11},
+	}, toTest.Origins())
+
+	o, ok := toTest.Origin("--map[test].key")
+	assert.True(t, ok)
+	assert.Equal(t, path+":8", o.String())
+
+	o, ok = toTest.Origin("map.test.key")
+	assert.True(t, ok)
+	assert.Equal(t, path+":8", o.String())
+
+	_, ok = toTest.Origin("map.test.value")
+	assert.False(t, ok)
+}
+
+func TestConfig_Origin_UnnamedYaml(t *testing.T) {
+	c := testConfig{}
+	toTest := NewConfig(&c)
+	assert.NoError(t, toTest.ParseYaml(strings.NewReader("string: hello")))
+
+	o, ok := toTest.Origin("string")
+	assert.True(t, ok)
+	assert.Equal(t, "yaml:1", o.String())
+}
+
+func TestConfig_Origin_Arguments(t *testing.T) {
+	c := testConfig{}
+	toTest := NewConfig(&c, WithDefaults(SetDefaults))
+	assert.NoError(t, toTest.ParseArguments(
+		"--bool",
+		"-s", "hello",
+		"--string-array=v1",
+		"--array[0].key=name0",
+		"--string-array=v2",
+		"--map[test].key=name",
+	))
+
+	expected := map[string]string{
+		"bool":             "argument #0",
+		"string":           "argument #1",
+		"string-array.[0]": "argument #3",
+		"string-array.[1]": "argument #5",
+		"array.[0].key":    "argument #4",
+		"array.[0].value":  "default",
+		"map.[test].key":   "argument #6",
+		"map.[test].value": "default",
+		"entry.value":      "default",
+	}
+	actual := map[string]string{}
+	for path, origin := range toTest.Origins() {
+		actual[path] = origin.String()
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestConfig_Origin_Environment(t *testing.T) {
+	c := testConfig{}
+	toTest := NewConfig(&c)
+	assert.NoError(t, toTest.ParseEnvironment(
+		"OTHER=--bool2",
+		"CFG_A=--bool",
+		"CFG_B=--map.test.key=name",
+	))
+
+	assert.Equal(t, map[string]Origin{
+		"bool":           {Kind: OriginEnvironment, Name: "CFG_A"},
+		"map.[test].key": {Kind: OriginEnvironment, Name: "CFG_B"},
+	}, toTest.Origins())
+}
+
+func TestConfig_Origin_Override(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+string: hello
+float: 1.2
+map:
+  first:
+    key: name
+array:
+  - key: name0
+  - key: name1
+`)
+
+	c := testConfig{}
+	toTest := NewConfig(&c)
+	assert.NoError(t, toTest.Load(
+		FileSource(path),
+		ArgumentSource("--string=world", "--map[second].key=name", "--array[0].value=value"),
+		MapSource("defaults", map[string]any{"bool": true}),
+	))
+
+	// maps and slices are replaced by the later sources
+	assert.Equal(t, map[string]Origin{
+		"bool":             {Kind: OriginMap, Name: "defaults"},
+		"string":           {Kind: OriginArgument, Index: 0},
+		"float":            {Kind: OriginFile, Name: path, Line: 3},
+		"map.[second].key": {Kind: OriginArgument, Index: 1},
+		"array.[0].value":  {Kind: OriginArgument, Index: 2},
+	}, toTest.Origins())
+	assert.Equal(t, map[string]testEntry{"second": {Key: "name"}}, c.CustomMap)
+}
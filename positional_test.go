@@ -0,0 +1,110 @@
+package yacl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type positionalConfig struct {
+	Verbose bool     `yaml:"verbose" short:"v"`
+	Name    string   `yaml:"name" short:"n" usage:"The name"`
+	Source  string   `yaml:"source" positional:"0" usage:"The source"`
+	Files   []string `yaml:"files" positional:"rest" usage:"The files"`
+}
+
+func TestConfig_Positionals(t *testing.T) {
+	c := positionalConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments(
+		"--verbose",
+		"src",
+		"-n", "name",
+		"file1",
+		"-",
+		"--",
+		"--not-a-flag",
+		"-v",
+	))
+	assert.Equal(t, positionalConfig{
+		Verbose: true,
+		Name:    "name",
+		Source:  "src",
+		Files:   []string{"file1", "-", "--not-a-flag", "-v"},
+	}, c)
+	assert.Equal(t, []string{"src", "file1", "-", "--not-a-flag", "-v"}, toTest.Positionals())
+
+	o, _ := toTest.Origin("files[2]")
+	assert.Equal(t, Origin{Kind: OriginArgument, Index: 7}, o)
+}
+
+func TestConfig_Positionals_Order(t *testing.T) {
+	c := positionalConfig{}
+
+	var args, expected []string
+	for i := 0; i < 15; i++ {
+		args = append(args, fmt.Sprintf("file%d", i))
+		expected = append(expected, fmt.Sprintf("file%d", i))
+	}
+
+	assert.NoError(t, NewConfig(&c).ParseArguments(args...))
+	assert.Equal(t, "file0", c.Source)
+	assert.Equal(t, expected[1:], c.Files)
+}
+
+func TestConfig_Positionals_WithoutFields(t *testing.T) {
+	c := testConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments("first", "--string=hello", "second", "--", "--bool"))
+	assert.Equal(t, testConfig{String: "hello"}, c)
+	assert.Equal(t, []string{"first", "second", "--bool"}, toTest.Positionals())
+}
+
+func TestConfig_Positionals_Environment(t *testing.T) {
+	c := positionalConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseEnvironment("CFG_0=src", "CFG_1=--name=name"))
+	assert.Equal(t, positionalConfig{Name: "name"}, c)
+	assert.Empty(t, toTest.Positionals())
+}
+
+func TestConfig_Positionals_Strict(t *testing.T) {
+	c := struct {
+		Source string `yaml:"source" positional:"0"`
+	}{}
+	err := NewConfig(&c, WithStrict(true)).ParseArguments("src", "surplus")
+
+	var ukErr *UnknownKeyError
+	assert.True(t, errors.As(err, &ukErr))
+	assert.Equal(t, []UnknownKey{
+		{Key: "surplus", Origin: Origin{Kind: OriginArgument, Index: 1}},
+	}, ukErr.Keys)
+}
+
+func TestConfig_Positionals_HelpFlags(t *testing.T) {
+	c := positionalConfig{}
+
+	expected := `Usage: [options] <source> [<files>...]
+
+  -v, --verbose=bool
+  -n, --name=string
+      	The name
+      --source=string
+      	The source
+      --files=[]string
+      	The files
+`
+	assert.Equal(t, expected, NewConfig(&c).HelpFlags())
+}
+
+func TestReader_EndOfOptions(t *testing.T) {
+	r := newReader([]string{"--key=value", "--", "--other=value"}, nil, newDefaultOptions())
+	assert.Equal(t, []line{
+		{path: []string{"key"}, value: "value"},
+	}, r.collectLines())
+	assert.Equal(t, []string{"--other=value"}, r.positionalArgs())
+}
@@ -0,0 +1,215 @@
+package yacl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml/ast"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// profilesKey is the top-level key of the section which contains the values of each profile (see WithProfiles).
+const profilesKey = "profiles"
+
+// Profiles returns the active profiles (see WithProfiles) in the order in which they are applied.
+func (c *Config) Profiles() []string {
+	return slices.Clone(c.profiles)
+}
+
+// profileFlag returns the flag which selects the active profiles (e.g. "--profile").
+func (c *Config) profileFlag() string {
+	return c.options.prefixLong + c.options.profileKey
+}
+
+// profileEnv returns the name of the environment variable which selects the active profiles (e.g. "CFG_PROFILE").
+func (c *Config) profileEnv() string {
+	return c.options.prefixEnv + strings.ToUpper(c.options.profileKey)
+}
+
+// selectProfiles returns the profiles which are selected by the given sources. The selection of a source with a
+// higher precedence replaces the selection of the sources with a lower precedence. Without any selection the
+// profiles of WithActiveProfiles are active.
+func (c *Config) selectProfiles(sources []Source) []string {
+	profiles := c.options.activeProfiles
+	for _, s := range sources {
+		if p, ok := s.(*precedenceSource); ok {
+			s = p.Source
+		}
+		if selector, ok := s.(*source); ok && selector.profiles != nil {
+			if selected := selector.profiles(c); selected != nil {
+				profiles = selected
+			}
+		}
+	}
+	return slices.Clone(profiles)
+}
+
+// profilesOfArgs returns the profiles which are selected by the given arguments (e.g. "--profile=prod,local" or
+// "--profile prod"). The flag can be given multiple times. If the arguments contain no selection, nil is returned.
+func (c *Config) profilesOfArgs(args []string) []string {
+	var profiles []string
+	for i, arg := range args {
+		if arg == c.options.prefixLong {
+			// end of options
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, c.profileFlag()+string(c.options.assignSign)); ok {
+			profiles = append(profiles, splitProfiles(value)...)
+		} else if arg == c.profileFlag() && i+1 < len(args) && !isOption(args[i+1], c.options) {
+			profiles = append(profiles, splitProfiles(args[i+1])...)
+		}
+	}
+	return profiles
+}
+
+// profilesOfEnv returns the profiles which are selected by the given environment variables (e.g.
+// "CFG_PROFILE=prod,local"). If the environment contains no selection, nil is returned.
+func (c *Config) profilesOfEnv(env []string) []string {
+	for _, e := range env {
+		if value, ok := strings.CutPrefix(e, c.profileEnv()+"="); ok {
+			return splitProfiles(value)
+		}
+	}
+	return nil
+}
+
+func splitProfiles(value string) []string {
+	profiles := []string{}
+	for _, profile := range strings.Split(value, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// profilePath returns the path of the overlay file of the given profile (e.g. "config.yaml" -> "config.prod.yaml").
+func profilePath(name, profile string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + profile + ext
+}
+
+// activateProfiles activates the given profiles after files were already parsed (see ParseArguments and
+// ParseEnvironment): the sections and overlay files of the newly activated profiles are applied to these files. The
+// values of profiles which are no longer active are not reverted. If no profiles are given, nothing happens.
+func (c *Config) activateProfiles(profiles []string) error {
+	if !c.options.profiles || profiles == nil {
+		return nil
+	}
+
+	previous := c.profiles
+	c.profiles = slices.Clone(profiles)
+
+	for _, layer := range c.profileLayers {
+		for _, profile := range profiles {
+			if slices.Contains(previous, profile) {
+				continue
+			}
+			if err := layer(profile); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseProfileOverlays parses the overlay files of the active profiles (e.g. "config.prod.yaml") which belong to
+// the file of the given reader. Overlay files which do not exist are ignored.
+func (c *Config) parseProfileOverlays(reader any) error {
+	if !c.options.profiles {
+		return nil
+	}
+
+	var base includeBase
+	switch r := reader.(type) {
+	case *fsFile:
+		base = includeBase{fsys: r.fsys, name: r.name}
+	case interface{ Name() string }:
+		if _, isFile := r.(fs.File); !isFile {
+			return nil
+		}
+		base = includeBase{name: r.Name()}
+	default:
+		return nil
+	}
+
+	applyOverlay := func(profile string) error {
+		overlay := includeBase{fsys: base.fsys, name: profilePath(base.name, profile)}
+		if err := c.parseProfileOverlay(overlay, profile); err != nil {
+			return fmt.Errorf("%s: %w", overlay.name, err)
+		}
+		return nil
+	}
+	c.profileLayers = append(c.profileLayers, applyOverlay)
+
+	for _, profile := range c.profiles {
+		if err := applyOverlay(profile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) parseProfileOverlay(overlay includeBase, profile string) error {
+	reader, err := overlay.open()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	previous := c.profile
+	c.profile = profile
+	defer func() {
+		c.profile = previous
+	}()
+
+	return c.parse(reader)
+}
+
+// profileSections removes the profiles section of the given body and returns it (nil if there is no such section).
+func (c *Config) profileSections(body ast.Node) (*ast.MappingNode, error) {
+	mapping, ok := body.(*ast.MappingNode)
+	if !ok {
+		return nil, nil
+	}
+
+	i := slices.IndexFunc(mapping.Values, func(mv *ast.MappingValueNode) bool {
+		return yamlKey(mv.Key) == profilesKey
+	})
+	if i < 0 {
+		return nil, nil
+	}
+	value := mapping.Values[i].Value
+	mapping.Values = slices.Delete(mapping.Values, i, i+1)
+
+	if _, isNull := value.(*ast.NullNode); isNull {
+		return nil, nil
+	}
+	profiles, ok := value.(*ast.MappingNode)
+	if !ok {
+		pos := value.GetToken().Position
+		return nil, fmt.Errorf("[%d:%d] expected a mapping of profiles but got %s", pos.Line, pos.Column, value.Type())
+	}
+	return profiles, nil
+}
+
+// helpProfiles returns the help text for the flag which selects the profiles.
+func (c *Config) helpProfiles() string {
+	if !c.options.profiles {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\nProfiles:\n")
+	sb.WriteString("  " + c.profileFlag() + string(c.options.assignSign) + "string [$" + c.profileEnv() + "]\n")
+	sb.WriteString("  \tThe profiles to activate (comma separated)\n")
+	if len(c.profiles) > 0 {
+		sb.WriteString("  \tActive: " + strings.Join(c.profiles, ", ") + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,183 @@
+package yacl
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+type profileConfig struct {
+	URL   string   `yaml:"url" usage:"The url"`
+	Debug bool     `yaml:"debug"`
+	Hosts []string `yaml:"hosts"`
+	Name  string   `yaml:"name"`
+}
+
+func TestConfig_Profiles_Overlays(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":       "url: http://localhost\ndebug: true\nhosts: [a, b]\n",
+		"config.prod.yaml":  "url: https://example.com\ndebug: false\n",
+		"config.local.yaml": "hosts: [local]\n",
+	})
+
+	c := profileConfig{}
+	toTest := NewConfig(&c, WithProfiles(true), WithStrict(true))
+
+	assert.NoError(t, toTest.Load(
+		FileSource(filepath.Join(dir, "config.yaml")),
+		EnvironmentSource("CFG_PROFILE=staging"),
+		ArgumentSource("--profile=prod,missing", "--name=app", "--profile=local"),
+	))
+	assert.Equal(t, []string{"prod", "missing", "local"}, toTest.Profiles())
+	assert.Equal(t, "https://example.com", c.URL)
+	assert.False(t, c.Debug)
+	assert.Equal(t, []string{"local"}, c.Hosts)
+	assert.Equal(t, "app", c.Name)
+
+	o, _ := toTest.Origin("url")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "config.prod.yaml"), Line: 1, Profile: "prod"}, o)
+	assert.Equal(t, filepath.Join(dir, "config.prod.yaml")+":1 (profile prod)", o.String())
+	o, _ = toTest.Origin("name")
+	assert.Equal(t, Origin{Kind: OriginArgument, Index: 1}, o)
+}
+
+func TestConfig_Profiles_Environment(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":         "url: http://localhost\n",
+		"config.staging.yaml": "url: https://staging.example.com\n",
+	})
+
+	c := profileConfig{}
+	toTest := NewConfig(&c, WithActiveProfiles("prod"), WithStrict(true))
+	assert.Equal(t, []string{"prod"}, toTest.Profiles())
+
+	assert.NoError(t, toTest.Load(
+		FileSource(filepath.Join(dir, "config.yaml")),
+		EnvironmentSource("CFG_PROFILE=staging", "CFG_0=--name=env"),
+	))
+	assert.Equal(t, []string{"staging"}, toTest.Profiles())
+	assert.Equal(t, "https://staging.example.com", c.URL)
+	assert.Equal(t, "env", c.Name)
+}
+
+func TestConfig_Profiles_Section(t *testing.T) {
+	c := profileConfig{}
+	toTest := NewConfig(&c, WithActiveProfiles("prod", "local"), WithStrict(true))
+
+	assert.NoError(t, toTest.ParseYaml(bytes.NewBufferString(`
+url: http://localhost
+debug: true
+profiles:
+  local:
+    debug: true
+  prod:
+    url: https://example.com
+    debug: false
+  dev:
+    unknown: ignored
+`)))
+	assert.Equal(t, "https://example.com", c.URL)
+	assert.True(t, c.Debug)
+
+	o, _ := toTest.Origin("url")
+	assert.Equal(t, Origin{Kind: OriginFile, Line: 8, Profile: "prod"}, o)
+	o, _ = toTest.Origin("debug")
+	assert.Equal(t, Origin{Kind: OriginFile, Line: 6, Profile: "local"}, o)
+}
+
+func TestConfig_Profiles_Section_Error(t *testing.T) {
+	c := profileConfig{}
+	toTest := NewConfig(&c, WithProfiles(true))
+
+	err := toTest.ParseYaml(bytes.NewBufferString("url: http://localhost\nprofiles: [prod]\n"))
+	assert.EqualError(t, err, "[2:11] expected a mapping of profiles but got Sequence")
+}
+
+func TestConfig_Profiles_ParseFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":      "url: http://localhost\n",
+		"config.prod.yaml": "url: https://example.com\n",
+	})
+
+	c := profileConfig{}
+	assert.NoError(t, NewConfig(&c, WithActiveProfiles("prod")).ParseFile(filepath.Join(dir, "config.yaml")))
+	assert.Equal(t, "https://example.com", c.URL)
+
+	c = profileConfig{}
+	assert.NoError(t, NewConfig(&c).ParseFile(filepath.Join(dir, "config.yaml")))
+	assert.Equal(t, "http://localhost", c.URL)
+}
+
+func TestConfig_Profiles_HelpFlags(t *testing.T) {
+	c := profileConfig{}
+	toTest := NewConfig(&c, WithProfiles(true))
+
+	assert.NoError(t, toTest.Load(ArgumentSource("--profile=prod,local")))
+	assert.Equal(t, `  --url=string
+  	The url
+  --debug=bool
+  --hosts=[]string
+  --name=string
+
+Profiles:
+  --profile=string [$CFG_PROFILE]
+  	The profiles to activate (comma separated)
+  	Active: prod, local
+`, toTest.HelpFlags())
+}
+
+func TestConfig_Profiles_SpaceSeparated(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":      "url: http://localhost\n",
+		"config.prod.yaml": "url: https://example.com\n",
+	})
+
+	c := profileConfig{}
+	toTest := NewConfig(&c, WithProfiles(true), WithStrict(true))
+
+	assert.NoError(t, toTest.Load(
+		FileSource(filepath.Join(dir, "config.yaml")),
+		ArgumentSource("--profile", "prod", "--name=app"),
+	))
+	assert.Equal(t, []string{"prod"}, toTest.Profiles())
+	assert.Equal(t, "https://example.com", c.URL)
+	assert.Equal(t, "app", c.Name)
+	assert.Empty(t, toTest.Positionals())
+}
+
+func TestConfig_Profiles_ParseArguments(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":      "url: http://localhost\ndebug: true\nprofiles:\n  prod:\n    debug: false\n",
+		"config.prod.yaml": "url: https://example.com\nname: prod\n",
+	})
+
+	c := profileConfig{}
+	toTest := NewConfig(&c, WithProfiles(true), WithStrict(true))
+
+	assert.NoError(t, toTest.ParseFile(filepath.Join(dir, "config.yaml")))
+	assert.Equal(t, "http://localhost", c.URL)
+	assert.True(t, c.Debug)
+
+	assert.NoError(t, toTest.ParseArguments("--profile", "prod", "--name=app"))
+	assert.Equal(t, []string{"prod"}, toTest.Profiles())
+	assert.Equal(t, "https://example.com", c.URL)
+	assert.False(t, c.Debug)
+	assert.Equal(t, "app", c.Name)
+	assert.Empty(t, toTest.Positionals())
+
+	o, _ := toTest.Origin("url")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: filepath.Join(dir, "config.prod.yaml"), Line: 1, Profile: "prod"}, o)
+}
+
+func TestConfig_Profiles_ParseEnvironment(t *testing.T) {
+	c := profileConfig{}
+	toTest := NewConfig(&c, WithProfiles(true))
+
+	assert.NoError(t, toTest.ParseYaml(bytes.NewBufferString("url: http://localhost\nprofiles:\n  staging:\n    url: https://staging.example.com\n")))
+	assert.Equal(t, "http://localhost", c.URL)
+
+	assert.NoError(t, toTest.ParseEnvironment("CFG_PROFILE=staging"))
+	assert.Equal(t, []string{"staging"}, toTest.Profiles())
+	assert.Equal(t, "https://staging.example.com", c.URL)
+}
@@ -3,14 +3,22 @@ package yacl
 import (
 	"fmt"
 	"io"
+	"reflect"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 var indexRegex = regexp.MustCompile(`^\[[0-9]+\]$`)
 var onlyNumberRegex = regexp.MustCompile(`^[0-9]+$`)
 
+// indexAbbreviationRegex matches the (unescaped) opening brackets which follow another character, so the key
+// delimiter can be inserted (e.g. "array[0]" -> "array.[0]"). It doesn't depend on the delimiter: a duplicated
+// delimiter is no problem, because splitPreservingBrackets skips empty segments.
+var indexAbbreviationRegex = regexp.MustCompile(`([^\\])\[`)
+
 type Reader struct {
 	r *io.PipeReader
 	w *io.PipeWriter
@@ -24,11 +32,23 @@ type Reader struct {
 	reKeyValFlag      *regexp.Regexp
 	reKeyValShort     *regexp.Regexp
 	reKeyValShortFlag *regexp.Regexp
-	reIndexApprev     *regexp.Regexp
 
 	options    Options
 	fieldInfos *fieldInfos
 	args       []string
+
+	// envNames contains the names of the environment variables the arguments came from (if any)
+	envNames []string
+	// lineArgs maps the lines of the generated yaml to the index of the argument they came from
+	lineArgs map[int]int
+	// skipped contains the indices of the arguments which could not be interpreted
+	skipped []int
+	// positionals contains the indices of the positional arguments
+	positionals []int
+	// indices maps the arguments to their index inside the original arguments (if they are a part of them)
+	indices []int
+	// consumed contains the flags which are consumed by the config itself (see Config.consumedFlags)
+	consumed []string
 }
 
 func newReaderWithoutSort(args []string, dst *fieldInfos, options Options) *Reader {
@@ -44,20 +64,31 @@ func newReader(args []string, dst *fieldInfos, options Options) *Reader {
 		args:       args,
 		fieldInfos: dst,
 		options:    options,
+		lineArgs:   map[int]int{},
 	}
 	r.r, r.w = io.Pipe()
 
-	r.reKeyVal = regexp.MustCompile(`^` + options.prefixLong + `([^` + string(options.assignSign) + `]*)` + string(options.assignSign) + `(.*)$`)
-	r.reKeyValShort = regexp.MustCompile(`^` + options.prefixShort + `([^` + string(options.assignSign) + `]*)` + string(options.assignSign) + `(.*)$`)
+	// values can contain line breaks and the keys can contain escaped assign signs (see escapeKey)
+	r.reKeyVal = regexp.MustCompile(`(?s)^` + options.prefixLong + `((?:\\.|[^\\` + string(options.assignSign) + `])*)` + string(options.assignSign) + `(.*)$`)
+	r.reKeyValShort = regexp.MustCompile(`(?s)^` + options.prefixShort + `([^` + string(options.assignSign) + `]*)` + string(options.assignSign) + `(.*)$`)
 
-	r.reKeyValFlag = regexp.MustCompile(`^` + options.prefixLong + `([^` + string(options.assignSign) + `]*)$`)
+	r.reKeyValFlag = regexp.MustCompile(`(?s)^` + options.prefixLong + `((?:\\.|[^\\` + string(options.assignSign) + `])*)$`)
 	r.reKeyValShortFlag = regexp.MustCompile(`^` + options.prefixShort + `([^` + string(options.assignSign) + `]*)$`)
 
-	r.reIndexApprev = regexp.MustCompile(`([^\` + string(options.keyDelimiter) + `])\[`)
-
 	return r
 }
 
+// origin returns the origin of the argument with the given index.
+func (r *Reader) origin(argIndex int) Origin {
+	if r.envNames != nil {
+		return Origin{Kind: OriginEnvironment, Name: r.envNames[argIndex]}
+	}
+	if r.indices != nil {
+		argIndex = r.indices[argIndex]
+	}
+	return Origin{Kind: OriginArgument, Index: argIndex}
+}
+
 func (r *Reader) Read(p []byte) (n int, err error) {
 	if !r.running {
 		go r.run()
@@ -73,34 +104,36 @@ func (r *Reader) Close() error {
 func (r *Reader) run() {
 	defer r.w.Close()
 
-	lines := r.collectLines()
+	lines, lineArgs := r.collectIndexedLines()
 	indentation := make(map[string]bool)
-	for _, l := range lines {
+	lineNo := 0
+	for li, l := range lines {
 		for i, segment := range l.path {
 			indent := strings.Repeat("  ", i)
 
 			key := strings.Join(l.path[:i+1], ".")
 			if !indentation[key] {
+				lineNo++
 				if i == len(l.path)-1 {
 					// "<indent><segment>: <value>"
+					r.lineArgs[lineNo] = lineArgs[li]
 					r.w.Write([]byte(indent))
 					segment = strings.TrimPrefix(segment, "[")
 					segment = strings.TrimSuffix(segment, "]")
 
-					if onlyNumberRegex.MatchString(segment) {
+					if r.isIndex(l.path, i, onlyNumberRegex.MatchString(segment)) {
 						// this is a primitive array value
 						r.w.Write([]byte("- "))
-						r.w.Write([]byte(r.quote(l.value)))
+						r.w.Write([]byte(r.quote(l.path, l.value)))
 						r.w.Write([]byte("\n"))
 					} else {
-						r.w.Write([]byte("\""))
-						r.w.Write([]byte(segment))
-						r.w.Write([]byte("\": "))
-						r.w.Write([]byte(r.quote(l.value)))
+						r.w.Write([]byte(r.yamlKey(l.path, i, segment)))
+						r.w.Write([]byte(": "))
+						r.w.Write([]byte(r.quote(l.path, l.value)))
 						r.w.Write([]byte("\n"))
 					}
 				} else {
-					if strings.HasPrefix(segment, "[") && indexRegex.MatchString(segment) {
+					if r.isIndex(l.path, i, indexRegex.MatchString(segment)) {
 						// "<indent>-"  (Array-Element)
 						r.w.Write([]byte(indent))
 						r.w.Write([]byte("-\n"))
@@ -109,9 +142,8 @@ func (r *Reader) run() {
 						r.w.Write([]byte(indent))
 						segment = strings.TrimPrefix(segment, "[")
 						segment = strings.TrimSuffix(segment, "]")
-						r.w.Write([]byte("\""))
-						r.w.Write([]byte(segment))
-						r.w.Write([]byte("\":\n"))
+						r.w.Write([]byte(r.yamlKey(l.path, i, segment)))
+						r.w.Write([]byte(":\n"))
 					}
 				}
 				indentation[key] = true
@@ -120,30 +152,159 @@ func (r *Reader) run() {
 	}
 }
 
+// isIndex checks if the segment at the given index of the path is the index of a slice. Numeric segments can be the
+// keys of maps too, so the field infos decide. Without them (or for dynamic values) the given fallback is used.
+func (r *Reader) isIndex(path []string, i int, fallback bool) bool {
+	if container := r.container(path, i); container != nil {
+		return container.isSlice
+	}
+	return fallback
+}
+
+// container returns the node of the map or slice which contains the segment at the given index of the path (nil if
+// there is no such node).
+func (r *Reader) container(path []string, i int) *fieldPathNode {
+	if r.fieldInfos == nil || !strings.HasPrefix(path[i], "[") {
+		return nil
+	}
+	return r.fieldInfos.container(path, i)
+}
+
+// yamlKey returns the yaml representation of the given (unbracketed) key of the segment at the given index of the
+// path. The keys of maps with non-string keys (e.g. map[int]string) are not quoted, so they can be decoded.
+func (r *Reader) yamlKey(path []string, i int, key string) string {
+	if container := r.container(path, i); container != nil && container.isMap && container.mapKeyType.Kind() != reflect.String {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
 var reSpecialChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+var reSignedInteger = regexp.MustCompile(`^[-+][0-9]+$`)
 
-func (r *Reader) quote(rawValue string) string {
+func (r *Reader) quote(path []string, rawValue string) string {
 	if r.preventQuote {
 		return rawValue
 	}
 
-	if reSpecialChars.MatchString(rawValue) {
-		rawValue = strings.ReplaceAll(rawValue, "'", `\'`)
+	t := r.leafType(path)
+	switch {
+	case strings.ContainsFunc(rawValue, unicode.IsControl):
+		// control characters (like line breaks) are only allowed in escaped form
+		return strconv.Quote(rawValue)
+	case reSignedInteger.MatchString(rawValue) && isNumeric(t):
+		// quoted signed integers can not be decoded into numbers
+		return rawValue
+	case reSpecialChars.MatchString(rawValue) || (t != nil && t.Kind() == reflect.String):
+		// values of string fields are always quoted, so that they are not interpreted (e.g. "null" or "0x10")
+		// single quotes are escaped by doubling them
+		rawValue = strings.ReplaceAll(rawValue, "'", "''")
 		return fmt.Sprintf("'%s'", rawValue)
 	}
 	return rawValue
 }
 
+// leafType returns the type of the values of the field with the given path (e.g. the type of the elements of a
+// slice). If the field is unknown, nil will be returned.
+func (r *Reader) leafType(path []string) reflect.Type {
+	if r.fieldInfos == nil {
+		return nil
+	}
+	_, info := r.fieldInfos.normalizePath(path)
+	if info == nil || info.field.Type == nil {
+		return nil
+	}
+
+	t := info.field.Type
+	for !isScalarType(t) && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isNumeric checks if the given type is a number (and not a scalar type like time.Duration).
+func isNumeric(t reflect.Type) bool {
+	if t == nil || isScalarType(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
 type line struct {
 	path  []string
 	value string
 }
 
+// inCommand checks if the given path addresses a field of a subcommand (or the subcommand itself). Such fields can
+// only be set by the arguments which follow the name of the subcommand.
+func (r *Reader) inCommand(path []string) bool {
+	if r.fieldInfos == nil {
+		return false
+	}
+
+	raw := rawPath(path)
+	for i := range r.fieldInfos.fi {
+		info := &r.fieldInfos.fi[i]
+		depth := slices.IndexFunc(info.path, func(node *fieldPathNode) bool {
+			return node.command != ""
+		})
+		if depth < 0 || depth >= len(raw) {
+			continue
+		}
+		if _, ok := info.match(raw, true); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Reader) collectLines() []line {
-	lines := make([]line, 0, len(r.args))
+	lines, _ := r.collectIndexedLines()
+	return lines
+}
+
+// collectIndexedLines collects the lines and additionally returns the index of the argument of each line.
+func (r *Reader) collectIndexedLines() ([]line, []int) {
+	type indexedLine struct {
+		line
+		arg int
+	}
+	lines := make([]indexedLine, 0, len(r.args))
+	endOfOptions := false
 
 	for i := 0; i < len(r.args); i += 1 {
-		key := strings.ReplaceAll(r.args[i], "\n", "\\n")
+		argIndex := i
+		if r.envNames == nil {
+			// positional arguments are only supported for "real" arguments
+			if !endOfOptions && r.args[i] == r.options.prefixLong {
+				// "--" terminates the options: all following arguments are positional arguments
+				endOfOptions = true
+				continue
+			}
+			if endOfOptions || !isOption(r.args[i], r.options) {
+				r.positionals = append(r.positionals, argIndex)
+				continue
+			}
+		}
+
+		if flag, ok := consumedFlag(r.consumed, r.args[i], r.options); ok {
+			if r.args[i] == flag && i+1 < len(r.args) && !isOption(r.args[i+1], r.options) {
+				// "--config path": the next argument is the value of the flag
+				i += 1
+			}
+			continue
+		}
+
+		key := r.args[i]
 		var value string
 		var nextArg string
 		if i+1 < len(r.args) {
@@ -169,14 +330,18 @@ func (r *Reader) collectLines() []line {
 			}
 			return true
 		}()
-		if skip {
-			continue
-		}
-
 		// replace "array[0]" -> "array.[0]", "map[key].value" -> "map.[key].value"
-		key = r.reIndexApprev.ReplaceAllString(key, fmt.Sprintf("$1%c[", r.options.keyDelimiter))
+		key = indexAbbreviationRegex.ReplaceAllString(key, fmt.Sprintf("$1%c[", r.options.keyDelimiter))
 
 		path := r.splitPreservingBrackets(key)
+		for i := range path {
+			path[i] = unescapeKey(path[i], r.options.assignSign)
+		}
+		if skip || len(path) == 0 || (r.envNames == nil && r.inCommand(path)) {
+			r.skipped = append(r.skipped, argIndex)
+			continue
+		}
+
 		if r.fieldInfos != nil {
 			lastNode := path[len(path)-1]
 			if !strings.HasSuffix(lastNode, "]") {
@@ -194,20 +359,114 @@ func (r *Reader) collectLines() []line {
 			}
 		}
 
-		lines = append(lines, line{
-			path:  path,
-			value: value,
+		lines = append(lines, indexedLine{
+			line: line{
+				path:  path,
+				value: value,
+			},
+			arg: argIndex,
+		})
+	}
+
+	// assign the positional arguments to their fields
+	for p, argIndex := range r.positionals {
+		path := r.positionalPath(p, argIndex)
+		if path == nil {
+			r.skipped = append(r.skipped, argIndex)
+			continue
+		}
+
+		lines = append(lines, indexedLine{
+			line: line{
+				path:  path,
+				value: r.args[argIndex],
+			},
+			arg: argIndex,
 		})
 	}
 
 	// sort lines
 	if !r.preventSort {
-		slices.SortFunc(lines, func(a, b line) int {
-			return strings.Compare(strings.Join(a.path, ""), strings.Join(b.path, ""))
+		slices.SortStableFunc(lines, func(a, b indexedLine) int {
+			return strings.Compare(sortKey(a.path), sortKey(b.path))
 		})
 	}
 
-	return lines
+	result := make([]line, len(lines))
+	argIndexes := make([]int, len(lines))
+	for i := range lines {
+		result[i] = lines[i].line
+		argIndexes[i] = lines[i].arg
+	}
+	return result, argIndexes
+}
+
+// positionalArgs returns all positional arguments.
+func (r *Reader) positionalArgs() []string {
+	result := make([]string, len(r.positionals))
+	for i, argIndex := range r.positionals {
+		result[i] = r.args[argIndex]
+	}
+	return result
+}
+
+// sortKey returns the key for sorting the given path. Indices are padded, so that they are sorted numerically.
+func sortKey(path []string) string {
+	var sb strings.Builder
+	for _, segment := range path {
+		if indexRegex.MatchString(segment) {
+			sb.WriteString(fmt.Sprintf("[%020s]", segment[1:len(segment)-1]))
+		} else {
+			sb.WriteString(segment)
+		}
+	}
+	return sb.String()
+}
+
+// consumedFlag checks if the given argument is one of the given consumed flags (with or without value). It returns
+// the matching flag.
+func consumedFlag(consumed []string, arg string, options Options) (string, bool) {
+	for _, flag := range consumed {
+		if arg == flag || strings.HasPrefix(arg, flag+string(options.assignSign)) {
+			return flag, true
+		}
+	}
+	return "", false
+}
+
+// isOption checks if the given argument is an option (and not a positional argument).
+func isOption(arg string, options Options) bool {
+	if strings.HasPrefix(arg, options.prefixLong) {
+		return true
+	}
+	// a single prefix (like "-") is a positional argument (mostly used for stdin)
+	return options.prefixShort != "" && strings.HasPrefix(arg, options.prefixShort) && arg != options.prefixShort
+}
+
+// positionalPath returns the path of the field which is responsible for the p-th positional argument.
+// If there is no such field, nil will be returned.
+func (r *Reader) positionalPath(p int, argIndex int) []string {
+	if r.fieldInfos == nil {
+		return nil
+	}
+
+	info := r.fieldInfos.findByPositional(strconv.Itoa(p))
+	if info == nil {
+		info = r.fieldInfos.findByPositional(positionalRest)
+	}
+	if info == nil {
+		return nil
+	}
+
+	path := make([]string, len(info.path))
+	for i, node := range info.path {
+		path[i] = node.key
+	}
+	if info.path[len(info.path)-1].isSlice {
+		// the argument index is only used for the order of the elements (see primitive slices)
+		path = append(path, fmt.Sprintf("[%d]", argIndex))
+	}
+	return path
 }
 
 func (r *Reader) tryLong(line string, key, value *string) bool {
@@ -287,19 +546,30 @@ func (r *Reader) tryShortFlag(line string, key, value, next *string, i int) (mat
 }
 
 func (r *Reader) splitPreservingBrackets(s string) []string {
+	return splitPreservingBrackets(s, r.options.keyDelimiter)
+}
+
+func splitPreservingBrackets(s string, delimiter rune) []string {
 	var result []string
 	var current strings.Builder
 	inBrackets := false
 
 	for i := 0; i < len(s); i++ {
 		switch rune(s[i]) {
+		case '\\':
+			// the escaped character is kept (see escapeKey)
+			current.WriteByte(s[i])
+			if i+1 < len(s) {
+				i++
+				current.WriteByte(s[i])
+			}
 		case '[':
 			inBrackets = true
 			current.WriteByte(s[i])
 		case ']':
 			inBrackets = false
 			current.WriteByte(s[i])
-		case r.options.keyDelimiter:
+		case delimiter:
 			if inBrackets {
 				current.WriteByte(s[i])
 			} else if current.Len() > 0 {
@@ -317,3 +587,47 @@ func (r *Reader) splitPreservingBrackets(s string) []string {
 
 	return result
 }
+
+// escapeKey escapes the characters of the given map key which would break the parsing of arguments: the assign
+// sign, brackets and backslashes are prefixed by a backslash.
+func escapeKey(key string, assign rune) string {
+	var sb strings.Builder
+	for _, c := range key {
+		if c == assign || c == '[' || c == ']' || c == '\\' {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// unescapeKey removes the backslashes of the given path segment which were added by escapeKey. All other
+// backslashes are kept.
+func unescapeKey(segment string, assign rune) string {
+	if !strings.ContainsRune(segment, '\\') {
+		return segment
+	}
+
+	var sb strings.Builder
+	runes := []rune(segment)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune(string(assign)+`[]\`, runes[i+1]) {
+			i++
+		}
+		sb.WriteRune(runes[i])
+	}
+	return sb.String()
+}
+
+// cutArgument slices the given argument around the first assign sign which is not escaped (see escapeKey).
+func cutArgument(arg string, assign rune) (key, value string, found bool) {
+	for i := 0; i < len(arg); i++ {
+		switch {
+		case arg[i] == '\\':
+			i++
+		case strings.HasPrefix(arg[i:], string(assign)):
+			return arg[:i], arg[i+len(string(assign)):], true
+		}
+	}
+	return arg, "", false
+}
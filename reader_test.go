@@ -95,10 +95,14 @@ func TestReader_Quoting(t *testing.T) {
 	args := []string{
 		"--string1=hello: from another world",
 		"--string2=hello:\nfrom another world",
+		"--string3=it's",
+		"--int=-42",
 	}
 	expected := `
+"int": '-42'
 "string1": 'hello: from another world'
-"string2": 'hello:\nfrom another world'
+"string2": "hello:\nfrom another world"
+"string3": 'it''s'
 `
 
 	result, err := io.ReadAll(newReader(args, nil, newDefaultOptions()))
@@ -106,6 +110,50 @@ func TestReader_Quoting(t *testing.T) {
 	assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(string(result)))
 }
 
+func TestReader_Quoting_SignedNumbers(t *testing.T) {
+	testStruct := struct {
+		Host   string           `yaml:"host"`
+		Code   string           `yaml:"code"`
+		Phone  string           `yaml:"phone"`
+		Int    int              `yaml:"int"`
+		Float  float64          `yaml:"float"`
+		Ints   []int            `yaml:"ints"`
+		Labels map[string]*uint `yaml:"labels"`
+	}{}
+	args := []string{
+		"--host=+42",
+		"--code=-007",
+		"--phone=+4930123456",
+		"--int=-42",
+		"--float=+1",
+		"--ints=-1",
+		"--labels[a]=+2",
+	}
+	infos := NewConfig(&testStruct).collectInfos()
+
+	result, err := io.ReadAll(newReader(args, infos, newDefaultOptions()))
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(`
+"code": '-007'
+"float": +1
+"host": '+42'
+"int": -42
+"ints":
+  - -1
+"labels":
+  "a": +2
+"phone": '+4930123456'
+`), strings.TrimSpace(string(result)))
+
+	assert.NoError(t, NewConfig(&testStruct).ParseArguments(args...))
+	assert.Equal(t, "+42", testStruct.Host)
+	assert.Equal(t, "-007", testStruct.Code)
+	assert.Equal(t, "+4930123456", testStruct.Phone)
+	assert.Equal(t, -42, testStruct.Int)
+	assert.Equal(t, 1.0, testStruct.Float)
+	assert.Equal(t, []int{-1}, testStruct.Ints)
+}
+
 func TestReader_Short(t *testing.T) {
 	testStruct := struct {
 		String string `yaml:"string" short:"s"`
@@ -131,7 +179,7 @@ func TestReader_Short(t *testing.T) {
 "inner":
   "bool": true
   "int": 42
-"string": string
+"string": 'string'
 `
 
 	result, err := io.ReadAll(newReader(args, infos, newDefaultOptions()))
@@ -161,7 +209,7 @@ func TestReader_Short_Split(t *testing.T) {
 "inner":
   "bool": true
   "int": 42
-"string": string
+"string": 'string'
 `
 
 	result, err := io.ReadAll(newReader(args, infos, newDefaultOptions()))
@@ -0,0 +1,219 @@
+package yacl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HelpMarkdown returns a reference of all flags in Markdown format. The flags are grouped by their nested structs.
+// Each flag contains its short form, type, usage, default value, environment variable (see EnvModeNamed) and yaml
+// path. The options are the same as for HelpFlags.
+func (c *Config) HelpMarkdown(opts ...HelpOption) string {
+	return c.helpReference(opts...).HelpMarkdown()
+}
+
+// ManPage returns a reference of all flags as man page (roff format) with the given section and name. The content
+// is the same as in HelpMarkdown. The options are the same as for HelpFlags.
+func (c *Config) ManPage(section int, name string, opts ...HelpOption) string {
+	return c.helpReference(opts...).ManPage(section, name)
+}
+
+func (c *Config) helpReference(opts ...HelpOption) *fieldInfos {
+	options := newDefaultHelpOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	path := options.command
+	if path == nil {
+		path = c.Command()
+	}
+
+	infos := c.helpCommand(path)
+	options.apply(infos)
+	if infos.globals != nil {
+		options.apply(infos.globals)
+	}
+	return infos
+}
+
+// flagGroup contains all flags of one nested struct.
+type flagGroup struct {
+	// name is the path of the nested struct (e.g. "database.replicas[int]"). It is empty for the top level.
+	name  string
+	infos []*fieldInfo
+}
+
+// groups groups the flags by their nested structs. The groups are ordered by their first flag, the flags inside
+// the groups keep their order.
+func (f *fieldInfos) groups() []flagGroup {
+	var result []flagGroup
+	index := map[string]int{}
+	for i := range f.fi {
+		info := &f.fi[i]
+
+		name := ""
+		if len(info.path) > 1 {
+			name = strings.ReplaceAll(info.path[:len(info.path)-1].key(f.options, "int"), string(f.options.keyDelimiter)+"[", "[")
+		}
+
+		if _, ok := index[name]; !ok {
+			index[name] = len(result)
+			result = append(result, flagGroup{name: name})
+		}
+		result[index[name]].infos = append(result[index[name]].infos, info)
+	}
+	return result
+}
+
+// referenceProperties returns the properties of the given flag (in order) which are shown in the references.
+func (f *fieldInfos) referenceProperties(info *fieldInfo) [][2]string {
+	properties := [][2]string{{"Type", info.helpType()}}
+	if info.defaultValue != nil {
+		properties = append(properties, [2]string{"Default", info.helpDefault()})
+	}
+	if info.required {
+		properties = append(properties, [2]string{"Required", "yes"})
+	}
+	if f.options.envMode == EnvModeNamed {
+		properties = append(properties, [2]string{"Environment", info.envName(f.options)})
+	}
+	properties = append(properties, [2]string{"YAML", info.Path()})
+	return properties
+}
+
+func (f *fieldInfos) HelpMarkdown() string {
+	var sb strings.Builder
+
+	if synopsis := f.synopsis(); synopsis != "" {
+		sb.WriteString(fmt.Sprintf("`%s`\n\n", synopsis))
+	}
+
+	f.markdownFlags(&sb, "Options")
+
+	if len(f.commands) > 0 {
+		sb.WriteString("## Commands\n\n")
+		for _, cmd := range f.commands {
+			sb.WriteString(fmt.Sprintf("- `%s`", cmd.name))
+			if cmd.usage != "" {
+				sb.WriteString(": " + strings.ReplaceAll(cmd.usage, "\n", " "))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if f.globals != nil && len(f.globals.fi) > 0 {
+		f.globals.markdownFlags(&sb, "Global options")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (f *fieldInfos) markdownFlags(sb *strings.Builder, title string) {
+	for _, group := range f.groups() {
+		if group.name == "" {
+			sb.WriteString(fmt.Sprintf("## %s\n\n", title))
+		} else {
+			sb.WriteString(fmt.Sprintf("## %s: %s\n\n", title, group.name))
+		}
+
+		for _, info := range group.infos {
+			sb.WriteString(fmt.Sprintf("### `%s`", info.flag(f.options)))
+			if info.short != "" {
+				sb.WriteString(fmt.Sprintf(", `%s%s`", f.options.prefixShort, info.short))
+			}
+			sb.WriteString("\n\n")
+
+			if usage := info.path.Usage(); usage != "" {
+				sb.WriteString(usage)
+				sb.WriteString("\n\n")
+			}
+
+			for _, property := range f.referenceProperties(info) {
+				sb.WriteString(fmt.Sprintf("- %s: `%s`\n", property[0], property[1]))
+			}
+			sb.WriteString("\n")
+		}
+	}
+}
+
+func (f *fieldInfos) ManPage(section int, name string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf(".TH %s %d\n", roffQuote(strings.ToUpper(name)), section))
+	sb.WriteString(".SH NAME\n")
+	sb.WriteString(roffEscape(name) + "\n")
+
+	if synopsis := f.synopsis(); synopsis != "" {
+		sb.WriteString(".SH SYNOPSIS\n")
+		sb.WriteString(fmt.Sprintf(".B %s\n", roffEscape(name)))
+		sb.WriteString(roffEscape(strings.TrimPrefix(synopsis, "Usage: ")) + "\n")
+	}
+
+	f.manFlags(&sb, "OPTIONS")
+
+	if len(f.commands) > 0 {
+		sb.WriteString(".SH COMMANDS\n")
+		for _, cmd := range f.commands {
+			sb.WriteString(".TP\n")
+			sb.WriteString(fmt.Sprintf(".B %s\n", roffEscape(cmd.name)))
+			if cmd.usage != "" {
+				sb.WriteString(roffEscape(cmd.usage) + "\n")
+			}
+		}
+	}
+
+	if f.globals != nil && len(f.globals.fi) > 0 {
+		f.globals.manFlags(&sb, "GLOBAL OPTIONS")
+	}
+
+	return sb.String()
+}
+
+func (f *fieldInfos) manFlags(sb *strings.Builder, title string) {
+	sb.WriteString(fmt.Sprintf(".SH %s\n", roffQuote(title)))
+
+	for _, group := range f.groups() {
+		if group.name != "" {
+			sb.WriteString(fmt.Sprintf(".SS %s\n", roffQuote(group.name)))
+		}
+
+		for _, info := range group.infos {
+			sb.WriteString(".TP\n")
+			flag := fmt.Sprintf(".B %s", roffEscape(info.flag(f.options)))
+			if info.short != "" {
+				flag = fmt.Sprintf(".BR %s \", \" %s", roffEscape(info.flag(f.options)), roffEscape(f.options.prefixShort+info.short))
+			}
+			sb.WriteString(flag + "\n")
+
+			if usage := info.path.Usage(); usage != "" {
+				sb.WriteString(roffEscape(usage) + "\n")
+			}
+			for _, property := range f.referenceProperties(info) {
+				sb.WriteString(".br\n")
+				sb.WriteString(roffEscape(property[0]+": "+property[1]) + "\n")
+			}
+		}
+	}
+}
+
+// roffEscape escapes the given text for roff: backslashes and dashes are escaped and lines must not start with
+// a control character.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// roffQuote escapes the given text for roff and quotes it (for macro arguments).
+func roffQuote(s string) string {
+	return `"` + strings.ReplaceAll(roffEscape(s), `"`, `""`) + `"`
+}
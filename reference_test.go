@@ -0,0 +1,136 @@
+package yacl
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type referenceConfig struct {
+	Verbose bool            `yaml:"verbose" short:"v" usage:"Verbose output"`
+	Source  string          `yaml:"source" positional:"0"`
+	Server  referenceServer `yaml:"server"`
+	Serve   *struct {
+		Host string `yaml:"host"`
+	} `yaml:"serve" command:"serve" usage:"Starts the server"`
+}
+
+type referenceServer struct {
+	Port     int    `yaml:"port" usage:"The port" required:"true"`
+	Password string `yaml:"password" secret:"true"`
+}
+
+func (r *referenceServer) SetDefaults() {
+	r.Port = 8080
+	r.Password = "secret"
+}
+
+func TestConfig_HelpMarkdown(t *testing.T) {
+	c := referenceConfig{}
+	toTest := NewConfig(&c, WithEnvMode(EnvModeNamed))
+
+	assert.Equal(t, "`Usage: [options] <source>`\n"+`
+## Options
+
+### `+"`--verbose`, `-v`"+`
+
+Verbose output
+
+- Type: `+"`bool`"+`
+- Environment: `+"`CFG_VERBOSE`"+`
+- YAML: `+"`verbose`"+`
+
+### `+"`--source`"+`
+
+- Type: `+"`string`"+`
+- Environment: `+"`CFG_SOURCE`"+`
+- YAML: `+"`source`"+`
+
+## Options: server
+
+### `+"`--server.port`"+`
+
+The port
+
+- Type: `+"`int`"+`
+- Default: `+"`8080`"+`
+- Required: `+"`yes`"+`
+- Environment: `+"`CFG_SERVER_PORT`"+`
+- YAML: `+"`server.port`"+`
+
+### `+"`--server.password`"+`
+
+- Type: `+"`string`"+`
+- Default: `+"`******`"+`
+- Environment: `+"`CFG_SERVER_PASSWORD`"+`
+- YAML: `+"`server.password`"+`
+
+## Commands
+
+- `+"`serve`"+`: Starts the server
+`, toTest.HelpMarkdown())
+}
+
+func TestConfig_HelpMarkdown_Command(t *testing.T) {
+	c := referenceConfig{}
+	toTest := NewConfig(&c)
+
+	markdown := toTest.HelpMarkdown(WithCommand("serve"))
+	assert.Contains(t, markdown, "## Options\n\n### `--host`\n")
+	assert.Contains(t, markdown, "## Global options\n\n### `--verbose`, `-v`\n")
+	assert.Contains(t, markdown, "## Global options: server\n")
+}
+
+func TestConfig_ManPage(t *testing.T) {
+	c := referenceConfig{}
+	toTest := NewConfig(&c)
+
+	assert.Equal(t, `.TH "MY\-APP" 1
+.SH NAME
+my\-app
+.SH SYNOPSIS
+.B my\-app
+[options] <source>
+.SH "OPTIONS"
+.TP
+.BR \-\-verbose ", " \-v
+Verbose output
+.br
+Type: bool
+.br
+YAML: verbose
+.TP
+.B \-\-source
+.br
+Type: string
+.br
+YAML: source
+.SS "server"
+.TP
+.B \-\-server.port
+The port
+.br
+Type: int
+.br
+Default: 8080
+.br
+Required: yes
+.br
+YAML: server.port
+.TP
+.B \-\-server.password
+.br
+Type: string
+.br
+Default: ******
+.br
+YAML: server.password
+.SH COMMANDS
+.TP
+.B serve
+Starts the server
+`, toTest.ManPage(1, "my-app"))
+}
+
+func TestRoffEscape(t *testing.T) {
+	assert.Equal(t, `\-\-flag \e n`+"\n"+`\&.TH`+"\n"+`\&'quoted'`, roffEscape("--flag \\ n\n.TH\n'quoted'"))
+}
@@ -0,0 +1,167 @@
+package yacl
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MissingRequiredError is returned if required fields (see WithRequired) are not provided by any source.
+type MissingRequiredError struct {
+	// Paths contains the flags of all missing fields (e.g. "--database.url", "--array[0].key").
+	Paths []string
+}
+
+func (e *MissingRequiredError) Error() string {
+	return "missing required options: " + strings.Join(e.Paths, ", ")
+}
+
+// CheckRequired checks if all required fields (see WithRequired and WithRequiredTag) of the destination struct
+// (and of the selected subcommands) are provided. A field is provided if its value is set by any source (including
+// the default values) or if it is not empty. Fields inside slices and maps are checked for each existing element.
+// If there are missing fields, a MissingRequiredError is returned.
+func (c *Config) CheckRequired() error {
+	var missing []string
+	c.checkRequired(&missing)
+	for _, cmd := range c.chain[min(1, len(c.chain)):] {
+		cmd.checkRequired(&missing)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	return &MissingRequiredError{Paths: missing}
+}
+
+func (c *Config) checkRequired(missing *[]string) {
+	for _, info := range c.collectInfos().fi {
+		if !info.required || info.inCommand() {
+			continue
+		}
+
+		c.walkFieldPath(reflect.ValueOf(c.dest), info.path, nil, func(path []string, v reflect.Value) {
+			if (v.IsValid() && !isEmpty(v)) || c.hasOrigin(path) {
+				return
+			}
+			*missing = append(*missing, c.flagOf(path))
+		})
+	}
+}
+
+// isRequired checks if the given field is marked as required (by tag or by option).
+func (c *Config) isRequired(info *fieldInfo) bool {
+	if required, _ := strconv.ParseBool(info.field.Tag.Get(c.options.requiredTag)); required {
+		return true
+	}
+
+	keys := make([]string, len(info.path))
+	for i, node := range info.path {
+		keys[i] = node.key
+	}
+
+	for _, path := range c.options.required {
+		// the map keys and slice indices are not relevant
+		segments := slices.DeleteFunc(c.splitKey(strings.TrimPrefix(path, c.options.prefixLong)), func(s string) bool {
+			return strings.HasPrefix(s, "[")
+		})
+		if slices.Equal(segments, keys) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOrigin checks if there is an origin for the given path (or for any value below it).
+func (c *Config) hasOrigin(path []string) bool {
+	joined := c.joinPath(path)
+	for p := range c.origins {
+		if p == joined || strings.HasPrefix(p, joined+string(c.options.keyDelimiter)) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkFieldPath walks along the given field path through the given value and calls fn with the normalized path
+// and the value of each existing field (one for each element of slices and maps). If a pointer on the way is nil,
+// fn is called with an invalid value.
+func (c *Config) walkFieldPath(v reflect.Value, nodes fieldPath, path []string, fn func(path []string, v reflect.Value)) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			for _, node := range nodes {
+				if node.isSlice || node.isMap {
+					// there are no elements which could be missing
+					return
+				}
+				path = append(slices.Clip(path), node.key)
+			}
+			fn(path, reflect.Value{})
+			return
+		}
+		v = v.Elem()
+	}
+
+	if len(nodes) == 0 {
+		fn(path, v)
+		return
+	}
+
+	field, ok := fieldByKey(v, nodes[0].key)
+	if !ok {
+		return
+	}
+	subPath := append(slices.Clip(path), nodes[0].key)
+
+	switch {
+	case len(nodes) == 1:
+		fn(subPath, field)
+	case nodes[0].isSlice:
+		for i := 0; i < field.Len(); i++ {
+			c.walkFieldPath(field.Index(i), nodes[1:], append(slices.Clip(subPath), fmt.Sprintf("[%d]", i)), fn)
+		}
+	case nodes[0].isMap:
+		keys := field.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, key := range keys {
+			c.walkFieldPath(field.MapIndex(key), nodes[1:], append(slices.Clip(subPath), fmt.Sprintf("[%v]", key.Interface())), fn)
+		}
+	default:
+		c.walkFieldPath(field, nodes[1:], subPath, fn)
+	}
+}
+
+// fieldByKey returns the field of the given struct which has the given yaml key (including inlined structs).
+func fieldByKey(v reflect.Value, key string) (reflect.Value, bool) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		yamlKey := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if yamlKey == key {
+			return v.Field(i), true
+		}
+		if yamlKey == "" && field.Tag.Get("yaml") != "" {
+			// inlined struct
+			inline := v.Field(i)
+			for inline.Kind() == reflect.Ptr && !inline.IsNil() {
+				inline = inline.Elem()
+			}
+			if result, ok := fieldByKey(inline, key); ok {
+				return result, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
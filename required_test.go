@@ -0,0 +1,82 @@
+package yacl
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type requiredConfig struct {
+	Database struct {
+		URL     string `yaml:"url" required:"true" usage:"The database url"`
+		Timeout int    `yaml:"timeout"`
+	} `yaml:"database"`
+	Debug   *bool                `yaml:"debug" required:"true"`
+	Entries []testEntry          `yaml:"entry"`
+	Map     map[string]testEntry `yaml:"map"`
+	Tags    []string             `yaml:"tags"`
+}
+
+func TestConfig_CheckRequired(t *testing.T) {
+	c := requiredConfig{}
+	err := NewConfig(&c, WithRequired("--entry[int].key", "tags")).ParseArguments(
+		"--entry[0].value=value",
+		"--entry[1].key=key",
+		"--database.timeout=12",
+	)
+
+	var mrErr *MissingRequiredError
+	assert.True(t, errors.As(err, &mrErr))
+	assert.Equal(t, []string{"--database.url", "--debug", "--entry[0].key", "--tags"}, mrErr.Paths)
+	assert.Equal(t, "missing required options: --database.url, --debug, --entry[0].key, --tags", err.Error())
+}
+
+func TestConfig_CheckRequired_Provided(t *testing.T) {
+	c := requiredConfig{}
+	toTest := NewConfig(&c, WithRequired("map.key"))
+
+	// the zero value is provided explicitly
+	assert.NoError(t, toTest.ParseArguments("--database.url=", "--debug=false", "--map[a].key=key"))
+	assert.Equal(t, false, *c.Debug)
+}
+
+func TestConfig_CheckRequired_Load(t *testing.T) {
+	c := requiredConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.Load(
+		FileSource(writeFile(t, "config.yaml", "database:\n  url: postgres://localhost")),
+		ArgumentSource("--debug"),
+	))
+
+	err := NewConfig(&requiredConfig{}).Load(
+		ArgumentSource("--debug"),
+	)
+	assert.EqualError(t, err, "missing required options: --database.url")
+}
+
+func TestConfig_CheckRequired_Defaults(t *testing.T) {
+	c := requiredConfig{}
+	toTest := NewConfig(&c, WithDefaults(func(r *requiredConfig) {
+		r.Database.URL = "postgres://localhost"
+		r.Debug = P(true)
+	}))
+
+	assert.NoError(t, toTest.ParseArguments())
+}
+
+func TestConfig_Required_Help(t *testing.T) {
+	c := struct {
+		Name string `yaml:"name" short:"n" required:"true" usage:"The name"`
+		Port int    `yaml:"port"`
+	}{}
+	toTest := NewConfig(&c, WithRequired("port"))
+
+	assert.Equal(t, `  -n, --name=string (required)
+      	The name
+      --port=int (required)
+`, toTest.HelpFlags())
+	assert.Equal(t, `"name": string # The name (required)
+"port": int # (required)`, strings.TrimSpace(toTest.HelpYaml()))
+}
@@ -0,0 +1,192 @@
+package yacl
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Resolver resolves references to values which are stored somewhere else (e.g. "file:///run/secrets/db_pass"
+// or "env:DB_PASS"). See WithResolver.
+type Resolver interface {
+	// Resolve returns the value behind the given reference. The reference is the part after the scheme
+	// (e.g. "///run/secrets/db_pass" for "file:///run/secrets/db_pass").
+	Resolve(reference string) (string, error)
+}
+
+// ResolverFunc is a function which implements the Resolver interface.
+type ResolverFunc func(reference string) (string, error)
+
+func (f ResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+// FileResolver returns a Resolver which reads the content of the referenced file ("file:///path/to/file" or
+// "file:path/to/file"). Trailing line breaks are removed.
+func FileResolver() Resolver {
+	return ResolverFunc(func(reference string) (string, error) {
+		content, err := os.ReadFile(strings.TrimPrefix(reference, "//"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	})
+}
+
+// EnvResolver returns a Resolver which reads the value of the referenced environment variable ("env:NAME").
+func EnvResolver() Resolver {
+	return ResolverFunc(func(reference string) (string, error) {
+		value, ok := os.LookupEnv(reference)
+		if !ok {
+			return "", fmt.Errorf("environment variable %q is not set", reference)
+		}
+		return value, nil
+	})
+}
+
+// ResolveError is returned if there are references which can not be resolved (see Config.Resolve).
+type ResolveError struct {
+	Fields []FieldError
+}
+
+func (e *ResolveError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = field.Error()
+	}
+	return "unresolvable values: " + strings.Join(fields, ", ")
+}
+
+// Resolve replaces all string values of the destination struct (and of the selected subcommands) which are
+// references (e.g. "file:///run/secrets/db_pass" or "env:DB_PASS") with the values behind them. The references
+// are resolved by the Resolver of their scheme (see WithResolver). Values with an unknown scheme are left as they
+// are. All references which can not be resolved are returned as ResolveError.
+func (c *Config) Resolve() error {
+	if len(c.options.resolvers) == 0 {
+		return nil
+	}
+
+	var fields []FieldError
+	c.resolveRecursive(reflect.ValueOf(c.dest), nil, &fields)
+	for _, cmd := range c.chain[min(1, len(c.chain)):] {
+		cmd.resolveRecursive(reflect.ValueOf(cmd.dest), nil, &fields)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ResolveError{Fields: fields}
+}
+
+func (c *Config) resolveRecursive(v reflect.Value, path []string, fields *[]FieldError) {
+	resolve := func(value string) (string, bool) {
+		resolved, err := c.resolveValue(value)
+		if err != nil {
+			*fields = append(*fields, FieldError{Flag: c.flagOf(path), Err: err})
+			return "", false
+		}
+		return resolved, resolved != value
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			c.resolveRecursive(v.Elem(), path, fields)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if s, ok := v.Interface().(string); ok {
+			if resolved, changed := resolve(s); changed && v.CanSet() {
+				v.Set(reflect.ValueOf(resolved))
+			}
+			return
+		}
+		c.resolveRecursive(v.Elem(), path, fields)
+	case reflect.String:
+		if resolved, changed := resolve(v.String()); changed && v.CanSet() {
+			v.SetString(resolved)
+		}
+	case reflect.Struct:
+		if isSecretType(v.Type()) {
+			// the wrapped value can only be set by the Secret itself
+			s, isString := unwrapSecret(v).Interface().(string)
+			setter, canSet := addrOf(v).(interface{ Set(string) })
+			if isString && canSet {
+				if resolved, changed := resolve(s); changed {
+					setter.Set(resolved)
+				}
+			}
+			return
+		}
+
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			yamlTag := field.Tag.Get("yaml")
+			if yamlTag == "" || yamlTag == "-" {
+				continue
+			}
+
+			subPath := path
+			if key := strings.Split(yamlTag, ",")[0]; key != "" {
+				subPath = append(slices.Clip(path), key)
+			}
+			c.resolveRecursive(v.Field(i), subPath, fields)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.resolveRecursive(v.Index(i), append(slices.Clip(path), fmt.Sprintf("[%d]", i)), fields)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, key := range keys {
+			// map values are not addressable
+			temp := reflect.New(v.Type().Elem()).Elem()
+			temp.Set(v.MapIndex(key))
+			c.resolveRecursive(temp, append(slices.Clip(path), fmt.Sprintf("[%v]", key.Interface())), fields)
+			v.SetMapIndex(key, temp)
+		}
+	default:
+		// ignore other types
+	}
+}
+
+// resolveValue resolves the given value if it is a reference with a known scheme. Otherwise, the value is
+// returned as it is.
+func (c *Config) resolveValue(value string) (string, error) {
+	scheme, reference, found := strings.Cut(value, ":")
+	if !found {
+		return value, nil
+	}
+
+	resolver, ok := c.options.resolvers[scheme]
+	if !ok {
+		return value, nil
+	}
+
+	resolved, err := resolver.Resolve(reference)
+	if err != nil {
+		return "", fmt.Errorf("can not resolve %s: %w", scheme, err)
+	}
+	return resolved, nil
+}
+
+// addrOf returns the address of the given value (if it is addressable) or nil.
+func addrOf(v reflect.Value) any {
+	if v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	return nil
+}
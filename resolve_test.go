@@ -0,0 +1,109 @@
+package yacl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type resolveConfig struct {
+	Password string            `yaml:"password"`
+	User     *string           `yaml:"user"`
+	Token    Secret[string]    `yaml:"token"`
+	Hosts    []string          `yaml:"hosts"`
+	Labels   map[string]string `yaml:"labels"`
+	Raw      map[string]any    `yaml:"raw"`
+	Port     int               `yaml:"port"`
+	URL      string            `yaml:"url"`
+}
+
+func TestConfig_Resolve(t *testing.T) {
+	path := writeFile(t, "db_pass", "my-password\n")
+	t.Setenv("RESOLVE_USER", "admin")
+
+	c := resolveConfig{}
+	toTest := NewConfig(&c, WithDefaultResolvers())
+
+	assert.NoError(t, toTest.ParseArguments(
+		"--password=file://"+path,
+		"--user=env:RESOLVE_USER",
+		"--token=file:"+path,
+		"--hosts=env:RESOLVE_USER",
+		"--labels[owner]=env:RESOLVE_USER",
+		"--raw[deep][key]=env:RESOLVE_USER",
+		"--url=https://example.com",
+	))
+	assert.Equal(t, "my-password", c.Password)
+	assert.Equal(t, "admin", *c.User)
+	assert.Equal(t, "my-password", c.Token.Get())
+	assert.Equal(t, []string{"admin"}, c.Hosts)
+	assert.Equal(t, map[string]string{"owner": "admin"}, c.Labels)
+	assert.Equal(t, map[string]any{"deep": map[string]any{"key": "admin"}}, c.Raw)
+	assert.Equal(t, "https://example.com", c.URL)
+}
+
+func TestConfig_Resolve_DisabledByDefault(t *testing.T) {
+	t.Setenv("RESOLVE_USER", "admin")
+
+	c := resolveConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments("--password=file:/etc/passwd", "--url=env:RESOLVE_USER"))
+	assert.Equal(t, "file:/etc/passwd", c.Password)
+	assert.Equal(t, "env:RESOLVE_USER", c.URL)
+}
+
+func TestConfig_Resolve_Load(t *testing.T) {
+	t.Setenv("RESOLVE_PASSWORD", "from-env")
+
+	c := resolveConfig{}
+	toTest := NewConfig(&c, WithDefaultResolvers())
+
+	assert.NoError(t, toTest.Load(
+		FileSource(writeFile(t, "config.yaml", "password: env:RESOLVE_PASSWORD")),
+	))
+	assert.Equal(t, "from-env", c.Password)
+
+	o, _ := toTest.Origin("password")
+	assert.Equal(t, OriginFile, o.Kind)
+}
+
+func TestConfig_Resolve_Errors(t *testing.T) {
+	c := resolveConfig{}
+	toTest := NewConfig(&c, WithDefaultResolvers())
+
+	err := toTest.ParseArguments("--password=env:RESOLVE_UNKNOWN", "--labels[key]=file:///not/existing")
+
+	var rErr *ResolveError
+	assert.True(t, errors.As(err, &rErr))
+	assert.Len(t, rErr.Fields, 2)
+	assert.Equal(t, "--password", rErr.Fields[0].Flag)
+	assert.EqualError(t, rErr.Fields[0], `--password can not resolve env: environment variable "RESOLVE_UNKNOWN" is not set`)
+	assert.Equal(t, "--labels[key]", rErr.Fields[1].Flag)
+}
+
+func TestConfig_Resolve_CustomResolver(t *testing.T) {
+	vault := map[string]string{"db/password": "from-vault"}
+
+	c := resolveConfig{}
+	toTest := NewConfig(&c,
+		WithDefaultResolvers(),
+		WithResolver("vault", ResolverFunc(func(reference string) (string, error) {
+			if value, ok := vault[reference]; ok {
+				return value, nil
+			}
+			return "", fmt.Errorf("secret %q not found", reference)
+		})),
+		WithResolver("env", nil),
+	)
+
+	assert.NoError(t, toTest.ParseArguments("--password=vault:db/password", "--user=env:HOME"))
+	assert.Equal(t, "from-vault", c.Password)
+	assert.Equal(t, "env:HOME", *c.User)
+
+	err := toTest.ParseYaml(strings.NewReader("password: vault:unknown"))
+	assert.NoError(t, err)
+	assert.EqualError(t, toTest.Resolve(), `unresolvable values: --password can not resolve vault: secret "unknown" not found`)
+}
@@ -0,0 +1,272 @@
+package yacl
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SampleYaml returns a commented sample configuration file in yaml-format. In contrast to HelpYaml, the output is a
+// valid configuration: the values are the default values (see DefaultSetter and WithDefaults) and the usage is
+// written as comment above each key. Empty values are replaced by placeholders which satisfy the validation rules
+// (like the first value of oneof). Optional fields without default value, required fields without placeholder,
+// secret fields and example entries of maps and slices are commented out. So the sample can be parsed by ParseYaml
+// without changing anything.
+func (c *Config) SampleYaml() string {
+	t := reflect.TypeOf(c.dest)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	v := reflect.New(t).Elem()
+	c.applyDefaultsRecursive(t, v)
+
+	w := sampleWriter{config: c, stack: []reflect.Type{t}}
+	w.writeStruct(t, v, 0, false)
+	return w.sb.String()
+}
+
+type sampleWriter struct {
+	config *Config
+	sb     strings.Builder
+
+	// stack contains the struct types which are currently written (to detect recursive types)
+	stack []reflect.Type
+}
+
+// line writes one line with the given indentation level. If commented is true, the line is commented out.
+func (w *sampleWriter) line(indent int, commented bool, text string) {
+	w.sb.WriteString(strings.Repeat("  ", indent))
+	if commented {
+		w.sb.WriteString("# ")
+	}
+	w.sb.WriteString(text)
+	w.sb.WriteString("\n")
+}
+
+func (w *sampleWriter) writeStruct(t reflect.Type, v reflect.Value, indent int, commented bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		yamlTag := field.Tag.Get("yaml")
+		if yamlTag == "" || yamlTag == "-" {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		key := strings.Split(yamlTag, ",")[0]
+		if key == "" {
+			// inline struct
+			inlineType := field.Type
+			if inlineType.Kind() == reflect.Ptr {
+				inlineType = inlineType.Elem()
+				if fieldValue.IsNil() {
+					fieldValue = reflect.New(inlineType)
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if inlineType.Kind() == reflect.Struct {
+				w.writeStruct(inlineType, fieldValue, indent, commented)
+			}
+			continue
+		}
+
+		required, _ := strconv.ParseBool(field.Tag.Get(w.config.options.requiredTag))
+		required = required || hasRule(field.Tag.Get(w.config.options.validateTag), "required")
+
+		usage := w.config.getUsage(t, field)
+		if required {
+			usage = strings.TrimSpace(usage + " (required)")
+		}
+		if usage != "" {
+			for _, line := range strings.Split(usage, "\n") {
+				w.line(indent, true, line)
+			}
+		}
+
+		secret := field.Tag.Get(w.config.options.secretTag) == "true"
+		rules := field.Tag.Get(w.config.options.validateTag)
+		w.writeValue(key+":", field.Type, fieldValue, indent, commented, !required, secret, rules)
+	}
+}
+
+// writeValue writes the given value behind the given head (which is the key, or the dash of a slice item).
+// Empty values are replaced by a placeholder which satisfies the given validation rules (see placeholder). They
+// will be commented out if they are optional or if there is no such placeholder. The values of secret fields are
+// never written (and always commented out).
+func (w *sampleWriter) writeValue(head string, t reflect.Type, v reflect.Value, indent int, commented, optional, secret bool, rules string) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if !v.IsValid() || v.IsNil() {
+			v = reflect.Value{}
+		} else {
+			v = v.Elem()
+		}
+	}
+
+	if secret || isSecretType(t) {
+		// the (default) value of secrets must not be revealed
+		if isSecretType(t) {
+			t = t.Field(0).Type
+		}
+		w.line(indent, true, head+" "+w.scalar(reflect.New(t).Elem()))
+		return
+	}
+
+	switch {
+	case isScalarType(t):
+		// fall through to scalars
+	case t.Kind() == reflect.Struct:
+		if slices.Contains(w.stack, t) {
+			// recursive types are only written once
+			w.line(indent, true, head+" {}")
+			return
+		}
+		if !v.IsValid() {
+			// a nil pointer: show the structure, but commented out
+			v = reflect.New(t).Elem()
+			w.config.applyDefaultsRecursive(t, v)
+			commented = commented || optional
+		}
+
+		w.line(indent, commented, head)
+		w.stack = append(w.stack, t)
+		w.writeStruct(t, v, indent+1, commented)
+		w.stack = w.stack[:len(w.stack)-1]
+		return
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if !v.IsValid() || v.Len() == 0 {
+			w.line(indent, true, head)
+			w.writeExample("-", t.Elem(), indent+1)
+			return
+		}
+
+		w.line(indent, commented, head)
+		for i := 0; i < v.Len(); i++ {
+			w.writeValue("-", t.Elem(), v.Index(i), indent+1, commented, false, false, "")
+		}
+		return
+	case t.Kind() == reflect.Map:
+		if !v.IsValid() || v.Len() == 0 {
+			w.line(indent, true, head)
+			w.writeExample(w.exampleKey(t.Key())+":", t.Elem(), indent+1)
+			return
+		}
+
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+
+		w.line(indent, commented, head)
+		for _, key := range keys {
+			w.writeValue(w.scalar(key)+":", t.Elem(), v.MapIndex(key), indent+1, commented, false, false, "")
+		}
+		return
+	case t.Kind() == reflect.Interface:
+		if !v.IsValid() || v.IsNil() {
+			w.line(indent, true, head)
+			return
+		}
+		w.writeAny(head, v.Elem().Interface(), indent, commented)
+		return
+	}
+
+	if !v.IsValid() {
+		v = reflect.New(t).Elem()
+	}
+	if !v.IsZero() {
+		w.line(indent, commented, head+" "+w.scalar(v))
+		return
+	}
+
+	value, valid := w.placeholder(t, rules, !optional)
+	if !valid {
+		value = w.scalar(v)
+	}
+	w.line(indent, commented || optional || !valid, head+" "+value)
+}
+
+// placeholder returns a value for an empty field which satisfies the given validation rules: the first value of
+// the oneof rule or the bound of the min rule (for numbers and durations). The empty value is returned, if it is
+// valid by itself (and not required). If there is no such value, false is returned.
+func (w *sampleWriter) placeholder(t reflect.Type, rules string, required bool) (string, bool) {
+	zero := reflect.New(t).Elem()
+	if !required && len(validateRules(zero, rules, true)) == 0 {
+		return w.scalar(zero), true
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch {
+		case name == "oneof":
+			value := strings.Split(arg, "|")[0]
+			if t.Kind() == reflect.String {
+				return w.scalar(reflect.ValueOf(value)), true
+			}
+			return value, true
+		case name == "min" && t.Kind() != reflect.String && t.Kind() != reflect.Slice && t.Kind() != reflect.Map:
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// writeExample writes an example entry (of a map or slice) of the given type. The entry is commented out.
+func (w *sampleWriter) writeExample(head string, t reflect.Type, indent int) {
+	w.writeValue(head, t, reflect.Value{}, indent, true, false, false, "")
+}
+
+// exampleKey returns the key of the example entry of maps with the given key type.
+func (w *sampleWriter) exampleKey(t reflect.Type) string {
+	if t.Kind() == reflect.String {
+		return "key"
+	}
+	return w.scalar(reflect.New(t).Elem())
+}
+
+// writeAny writes the given (untyped) value, for example the content of an interface field.
+func (w *sampleWriter) writeAny(head string, value any, indent int, commented bool) {
+	content, err := yaml.MarshalWithOptions(value, scalarEncodeOptions()...)
+	if err != nil {
+		w.line(indent, true, head)
+		return
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) == 1 && !strings.HasPrefix(lines[0], "- ") && !strings.Contains(lines[0], ": ") {
+		w.line(indent, commented, head+" "+lines[0])
+		return
+	}
+
+	w.line(indent, commented, head)
+	for _, line := range lines {
+		w.line(indent+1, commented, line)
+	}
+}
+
+// scalar returns the yaml representation of the given (leaf) value.
+func (w *sampleWriter) scalar(v reflect.Value) string {
+	if isScalarType(v.Type()) && v.IsZero() && (v.Kind() == reflect.Struct || v.Kind() == reflect.Slice) {
+		// the empty value is parsed as zero value (for example a nil net.IP)
+		return `""`
+	}
+	value := dumpValue(v)
+
+	_, isText := v.Interface().(encoding.TextMarshaler)
+	if v.Kind() == reflect.String || isText || isScalarType(v.Type()) {
+		// strings may have to be quoted
+		if content, err := yaml.Marshal(value); err == nil {
+			return strings.TrimSuffix(string(content), "\n")
+		}
+	}
+	return value
+}
@@ -0,0 +1,200 @@
+package yacl
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type sampleConfig struct {
+	Name    string                 `yaml:"name" usage:"The name" required:"true"`
+	Level   string                 `yaml:"level" usage:"The log level\n(debug or info)"`
+	Workers int                    `yaml:"workers"`
+	Timeout *time.Duration         `yaml:"timeout"`
+	Tags    []string               `yaml:"tags"`
+	Labels  map[string]string      `yaml:"labels"`
+	Server  sampleServer           `yaml:"server" usage:"The server"`
+	Backup  *sampleServer          `yaml:"backup"`
+	Entries []sampleEntry          `yaml:"entries"`
+	Routes  map[string]sampleEntry `yaml:"routes"`
+	Token   Secret[string]         `yaml:"token"`
+	Extra   any                    `yaml:"extra"`
+}
+
+type sampleServer struct {
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port" usage:"The port"`
+	Password string        `yaml:"password" secret:"true"`
+	Wait     time.Duration `yaml:"wait"`
+}
+
+func (s *sampleServer) SetDefaults() {
+	s.Host = "localhost"
+	s.Port = 8080
+	s.Password = "secret"
+	s.Wait = time.Second
+}
+
+type sampleEntry struct {
+	Key   string `yaml:"key"`
+	Value int    `yaml:"value"`
+}
+
+func (s *sampleConfig) SetDefaults() {
+	s.Level = "info"
+	s.Tags = []string{"a", "b c"}
+	s.Extra = map[string]any{"deep": []any{1, "two"}}
+}
+
+func TestConfig_SampleYaml(t *testing.T) {
+	c := sampleConfig{}
+	toTest := NewConfig(&c)
+
+	sample := toTest.SampleYaml()
+	assert.Equal(t, `# The name (required)
+# name: ""
+# The log level
+# (debug or info)
+level: info
+# workers: 0
+# timeout: 0s
+tags:
+  - a
+  - b c
+# labels:
+  # key: ""
+# The server
+server:
+  host: localhost
+  # The port
+  port: 8080
+  # password: ""
+  wait: 1s
+# backup:
+  # host: localhost
+  # The port
+  # port: 8080
+  # password: ""
+  # wait: 1s
+# entries:
+  # -
+    # key: ""
+    # value: 0
+# routes:
+  # key:
+    # key: ""
+    # value: 0
+# token: ""
+extra:
+  deep:
+  - 1
+  - two
+`, sample)
+
+	// the sample must be a valid configuration which contains the default values
+	parsed := sampleConfig{}
+	assert.NoError(t, NewConfig(&parsed, WithStrict(true)).ParseYaml(bytes.NewBufferString(sample)))
+	assert.Equal(t, "info", parsed.Level)
+	assert.Equal(t, []string{"a", "b c"}, parsed.Tags)
+	assert.Equal(t, "localhost", parsed.Server.Host)
+	assert.Equal(t, 8080, parsed.Server.Port)
+	assert.Equal(t, time.Second, parsed.Server.Wait)
+	assert.Empty(t, parsed.Server.Password)
+	assert.Nil(t, parsed.Backup)
+	assert.Empty(t, parsed.Entries)
+	assert.Equal(t, map[string]any{"deep": []any{uint64(1), "two"}}, parsed.Extra)
+}
+
+type sampleValidated struct {
+	Name     string        `yaml:"name" validate:"required"`
+	Level    string        `yaml:"level" validate:"required,oneof=debug|info"`
+	Format   string        `yaml:"format" validate:"oneof=text|json"`
+	Port     int           `yaml:"port" validate:"min=1"`
+	Workers  int           `yaml:"workers" required:"true" validate:"min=2,max=8"`
+	Timeout  time.Duration `yaml:"timeout" validate:"required,min=1s"`
+	Password string        `yaml:"password" secret:"true" validate:"required"`
+}
+
+func TestConfig_SampleYaml_Validated(t *testing.T) {
+	c := sampleValidated{}
+	toTest := NewConfig(&c)
+
+	sample := toTest.SampleYaml()
+	assert.Equal(t, `# (required)
+# name: ""
+# (required)
+level: debug
+# format: text
+# port: 1
+# (required)
+workers: 2
+# (required)
+timeout: 1s
+# (required)
+# password: ""
+`, sample)
+
+	// the sample is valid: the commented out values are given by the arguments
+	parsed := sampleValidated{}
+	toParse := NewConfig(&parsed, WithStrict(true))
+	assert.NoError(t, toParse.ParseYaml(bytes.NewBufferString(sample)))
+	assert.NoError(t, toParse.ParseArguments("--name=app", "--password=secret"))
+	assert.Equal(t, "debug", parsed.Level)
+	assert.Equal(t, 2, parsed.Workers)
+	assert.Equal(t, time.Second, parsed.Timeout)
+}
+
+type sampleValues struct {
+	Entries []sampleEntry          `yaml:"entries"`
+	Routes  map[string]sampleEntry `yaml:"routes"`
+	Ports   map[int]string         `yaml:"ports"`
+	Quoted  string                 `yaml:"quoted"`
+}
+
+func TestConfig_SampleYaml_Values(t *testing.T) {
+	c := sampleValues{}
+	toTest := NewConfig(&c, WithDefaults(func(s *sampleValues) {
+		s.Entries = []sampleEntry{{Key: "a", Value: 1}}
+		s.Routes = map[string]sampleEntry{"b": {Key: "b"}, "a": {Key: "a"}}
+		s.Quoted = "8080"
+	}))
+
+	// the map entries are sorted by their keys
+	assert.Equal(t, `entries:
+  -
+    key: a
+    value: 1
+routes:
+  a:
+    key: a
+    # value: 0
+  b:
+    key: b
+    # value: 0
+# ports:
+  # 0: ""
+quoted: "8080"
+`, toTest.SampleYaml())
+}
+
+func TestConfig_SampleYaml_PointerElements(t *testing.T) {
+	c := struct {
+		Entries []*sampleEntry          `yaml:"entries"`
+		Routes  map[string]*sampleEntry `yaml:"routes"`
+		Names   []*string               `yaml:"names"`
+	}{}
+	toTest := NewConfig(&c)
+
+	assert.Equal(t, `# entries:
+  # -
+    # key: ""
+    # value: 0
+# routes:
+  # key:
+    # key: ""
+    # value: 0
+# names:
+  # - ""
+`, toTest.SampleYaml())
+}
@@ -0,0 +1,513 @@
+package yacl
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+)
+
+// scalarType describes a well-known type which is represented by a single (text) value instead of its fields.
+type scalarType struct {
+	// name is the type name which is shown in the help (e.g. "duration")
+	name string
+	// format returns the text representation of the given value (a pointer to the type)
+	format func(v any) string
+	// parse sets the value (a pointer to the type) from the given text representation
+	parse func(dst any, text string) error
+
+	decodeOption yaml.DecodeOption
+	encodeOption yaml.EncodeOption
+}
+
+// newScalarType creates the scalarType for T: the values are parsed from their text representation by parse
+// (for all sources) and written out by format (for the dumps).
+func newScalarType[T any](name string, parse func(dst *T, text string) error, format func(v *T) string) scalarType {
+	parseText := func(dst *T, text string) error {
+		if text == "" {
+			// null or empty: reset to the zero value
+			var zero T
+			*dst = zero
+			return nil
+		}
+		return parse(dst, text)
+	}
+
+	return scalarType{
+		name: name,
+		format: func(v any) string {
+			return format(v.(*T))
+		},
+		parse: func(dst any, text string) error {
+			return parseText(dst.(*T), text)
+		},
+		decodeOption: yaml.CustomUnmarshaler(func(dst *T, bytes []byte) error {
+			var text string
+			if err := yaml.Unmarshal(bytes, &text); err != nil {
+				return err
+			}
+			return parseText(dst, text)
+		}),
+		encodeOption: yaml.CustomMarshaler(func(v T) ([]byte, error) {
+			return yaml.Marshal(format(&v))
+		}),
+	}
+}
+
+// timeLayouts are the layouts which are accepted for time values.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", time.DateOnly}
+
+// scalarTypes contains all well-known types which are treated as scalars.
+var scalarTypes = map[reflect.Type]scalarType{
+	reflect.TypeOf(time.Duration(0)): newScalarType("duration",
+		func(dst *time.Duration, text string) (err error) {
+			*dst, err = time.ParseDuration(text)
+			return
+		},
+		func(v *time.Duration) string { return v.String() },
+	),
+	reflect.TypeOf(time.Time{}): newScalarType("time",
+		func(dst *time.Time, text string) (err error) {
+			for _, layout := range timeLayouts {
+				if *dst, err = time.Parse(layout, text); err == nil {
+					return nil
+				}
+			}
+			return fmt.Errorf("invalid time %q: expected format %s", text, time.RFC3339)
+		},
+		func(v *time.Time) string { return v.Format(time.RFC3339Nano) },
+	),
+	reflect.TypeOf(net.IP{}): newScalarType("ip",
+		func(dst *net.IP, text string) error {
+			return dst.UnmarshalText([]byte(text))
+		},
+		func(v *net.IP) string { return v.String() },
+	),
+	reflect.TypeOf(net.IPNet{}): newScalarType("cidr",
+		func(dst *net.IPNet, text string) error {
+			_, ipNet, err := net.ParseCIDR(text)
+			if err != nil {
+				return err
+			}
+			*dst = *ipNet
+			return nil
+		},
+		func(v *net.IPNet) string { return v.String() },
+	),
+	reflect.TypeOf(url.URL{}): newScalarType("url",
+		func(dst *url.URL, text string) error {
+			u, err := url.Parse(text)
+			if err != nil {
+				return err
+			}
+			*dst = *u
+			return nil
+		},
+		func(v *url.URL) string { return v.String() },
+	),
+	reflect.TypeOf(regexp.Regexp{}): newScalarType("regexp",
+		func(dst *regexp.Regexp, text string) error {
+			return dst.UnmarshalText([]byte(text))
+		},
+		func(v *regexp.Regexp) string { return v.String() },
+	),
+	reflect.TypeOf(big.Int{}): newScalarType("bigint",
+		func(dst *big.Int, text string) error {
+			if _, ok := dst.SetString(text, 0); !ok {
+				return fmt.Errorf("invalid big integer: %q", text)
+			}
+			return nil
+		},
+		func(v *big.Int) string { return v.String() },
+	),
+	reflect.TypeOf(os.FileMode(0)): newScalarType("filemode",
+		func(dst *os.FileMode, text string) error {
+			// "0644" is octal, "420" is decimal
+			mode, err := strconv.ParseUint(text, 0, 32)
+			*dst = os.FileMode(mode)
+			return err
+		},
+		func(v *os.FileMode) string { return fmt.Sprintf("%#o", uint32(*v)) },
+	),
+}
+
+// Scalar can be implemented by custom types which are represented by a single value (like a log level or a
+// "host:port" pair). Such types are treated as one flag instead of a struct with fields: the values of all sources
+// are decoded by UnmarshalScalar and the dumps are written by MarshalScalar. Both methods must have a pointer
+// receiver. Types which implement encoding.TextUnmarshaler or yaml.BytesUnmarshaler are treated as scalars, too.
+type Scalar interface {
+	// UnmarshalScalar sets the value from the given text.
+	UnmarshalScalar(text string) error
+	// MarshalScalar returns the text representation of the value.
+	MarshalScalar() (string, error)
+}
+
+var (
+	scalarInterfaceType  = reflect.TypeOf((*Scalar)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	bytesUnmarshalerType = reflect.TypeOf((*yaml.BytesUnmarshaler)(nil)).Elem()
+)
+
+// lookupScalarType returns the scalarType of the given type (or of the type it points to). These are the
+// well-known types and all custom scalars (see Scalar).
+func lookupScalarType(t reflect.Type) (scalarType, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if st, ok := scalarTypes[t]; ok {
+		return st, true
+	}
+
+	pt := reflect.PointerTo(t)
+	if pt.Implements(scalarInterfaceType) || pt.Implements(textUnmarshalerType) || pt.Implements(bytesUnmarshalerType) {
+		return scalarType{name: t.String(), format: formatCustomScalar}, true
+	}
+	return scalarType{}, false
+}
+
+// isCustomScalar checks if the given type (or the type it points to) implements Scalar.
+func isCustomScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return reflect.PointerTo(t).Implements(scalarInterfaceType)
+}
+
+// formatCustomScalar returns the text representation of the given custom scalar (a pointer to the value).
+func formatCustomScalar(v any) string {
+	if s, ok := v.(Scalar); ok {
+		if text, err := s.MarshalScalar(); err == nil {
+			return text
+		}
+	}
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	if m, ok := v.(yaml.BytesMarshaler); ok {
+		if content, err := m.MarshalYAML(); err == nil {
+			return strings.TrimSpace(string(content))
+		}
+	}
+	return fmt.Sprint(reflect.ValueOf(v).Elem().Interface())
+}
+
+// isScalarType checks if the given type (or the type it points to) is a well-known scalar type or a custom scalar.
+func isScalarType(t reflect.Type) bool {
+	_, ok := lookupScalarType(t)
+	return ok
+}
+
+// formatScalar returns the text representation of the given value of a well-known scalar type.
+func formatScalar(st scalarType, v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		return st.format(v.Interface())
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return st.format(ptr.Interface())
+}
+
+// typeName returns the type name of the given (leaf) type as it is shown in the help.
+func typeName(t reflect.Type) string {
+	if st, ok := lookupScalarType(t); ok {
+		return st.name
+	}
+	return t.Kind().String()
+}
+
+func scalarDecodeOptions() []yaml.DecodeOption {
+	options := make([]yaml.DecodeOption, 0, len(scalarTypes))
+	for _, st := range scalarTypes {
+		options = append(options, st.decodeOption)
+	}
+	return options
+}
+
+func scalarEncodeOptions() []yaml.EncodeOption {
+	options := make([]yaml.EncodeOption, 0, len(scalarTypes))
+	for _, st := range scalarTypes {
+		options = append(options, st.encodeOption)
+	}
+	return options
+}
+
+// scalarNode is a yaml node which contains the value of a custom scalar (see Scalar).
+type scalarNode struct {
+	// node points to the node inside its parent, so it can be replaced
+	node *ast.Node
+	// path is the way to the value: yaml keys of struct fields (string), keys of maps (ast.MapKeyNode) and
+	// indices of slices (int)
+	path []any
+}
+
+// findScalarNodes collects all nodes inside the given yaml node which belong to a scalar of the given type. Which
+// scalar types are collected is decided by the given match function (e.g. isCustomScalar).
+func findScalarNodes(node *ast.Node, t reflect.Type, path []any, match func(reflect.Type) bool, result *[]scalarNode) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if *node == nil {
+		return
+	}
+	if match(t) {
+		*result = append(*result, scalarNode{node: node, path: path})
+		return
+	}
+	if isScalarType(t) || isSecretType(t) {
+		return
+	}
+
+	switch n := (*node).(type) {
+	case *ast.TagNode:
+		findScalarNodes(&n.Value, t, path, match, result)
+		return
+	case *ast.AnchorNode:
+		findScalarNodes(&n.Value, t, path, match, result)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		var values []*ast.MappingValueNode
+		switch n := (*node).(type) {
+		case *ast.MappingNode:
+			values = n.Values
+		case *ast.MappingValueNode:
+			values = []*ast.MappingValueNode{n}
+		}
+
+		for _, value := range values {
+			if t.Kind() == reflect.Map {
+				findScalarNodes(&value.Value, t.Elem(), append(slices.Clip(path), value.Key), match, result)
+			} else if field, ok := fieldTypeByKey(t, yamlKey(value.Key)); ok {
+				findScalarNodes(&value.Value, field, append(slices.Clip(path), yamlKey(value.Key)), match, result)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if sequence, ok := (*node).(*ast.SequenceNode); ok {
+			for i := range sequence.Values {
+				findScalarNodes(&sequence.Values[i], t.Elem(), append(slices.Clip(path), i), match, result)
+			}
+		}
+	}
+}
+
+// fieldTypeByKey returns the type of the field of the given struct type which has the given yaml key (including
+// inlined structs).
+func fieldTypeByKey(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		yamlTag := field.Tag.Get("yaml")
+		yamlKey := strings.Split(yamlTag, ",")[0]
+		if yamlKey == key && key != "" {
+			return field.Type, true
+		}
+		if yamlKey == "" && yamlTag != "" {
+			// inlined struct
+			inline := field.Type
+			if inline.Kind() == reflect.Ptr {
+				inline = inline.Elem()
+			}
+			if inline.Kind() == reflect.Struct {
+				if result, ok := fieldTypeByKey(inline, key); ok {
+					return result, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// decodeScalars calls the given decode function for the given yaml node and decodes the values of all scalars (see
+// isScalarType) afterward. Because the yaml decoder doesn't know the Scalar interface (and doesn't report the
+// position of failed values), their nodes are replaced by null while decoding.
+// The errors of arguments (and environment variables) refer to the flag of the value instead of the position
+// inside the generated yaml.
+func (c *Config) decodeScalars(body *ast.Node, isReader bool, decode func() error) error {
+	var nodes []scalarNode
+	findScalarNodes(body, reflect.TypeOf(c.dest), nil, isScalarType, &nodes)
+	if len(nodes) == 0 {
+		return decode()
+	}
+
+	originals := make([]ast.Node, len(nodes))
+	for i, sn := range nodes {
+		originals[i] = *sn.node
+		*sn.node = ast.Null(token.New("null", "null", originals[i].GetToken().Position))
+	}
+	err := decode()
+	for i, sn := range nodes {
+		*sn.node = originals[i]
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, sn := range nodes {
+		if _, isNull, _ := scalarText(originals[i]); isNull {
+			// already reset by the yaml decoder
+			continue
+		}
+
+		err := setScalar(reflect.ValueOf(c.dest), sn.path, originals[i])
+		if err != nil && isReader {
+			return FieldError{Flag: c.flagOf(scalarPath(sn.path)), Err: err}
+		}
+		if err != nil {
+			pos := originals[i].GetToken().Position
+			return fmt.Errorf("[%d:%d] %w", pos.Line, pos.Column, err)
+		}
+	}
+	return nil
+}
+
+// scalarPath converts the path of a scalarNode into path segments (e.g. "levels", "[db]").
+func scalarPath(path []any) []string {
+	segments := make([]string, len(path))
+	for i, key := range path {
+		switch k := key.(type) {
+		case string:
+			segments[i] = k
+		case int:
+			segments[i] = fmt.Sprintf("[%d]", k)
+		case ast.MapKeyNode:
+			segments[i] = "[" + yamlKey(k) + "]"
+		}
+	}
+	return segments
+}
+
+// scalarText returns the text of the given scalar node.
+func scalarText(node ast.Node) (text string, isNull bool, err error) {
+	switch n := node.(type) {
+	case *ast.TagNode:
+		return scalarText(n.Value)
+	case *ast.AnchorNode:
+		return scalarText(n.Value)
+	case *ast.NullNode:
+		return "", true, nil
+	case *ast.StringNode:
+		return n.Value, false, nil
+	case ast.ScalarNode:
+		return n.GetToken().Value, false, nil
+	default:
+		return "", false, fmt.Errorf("expected a single value but got %s", node.Type())
+	}
+}
+
+// setScalar decodes the given node into the scalar which is behind the given path of the given value.
+func setScalar(v reflect.Value, path []any, node ast.Node) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if len(path) == 0 {
+		return unmarshalScalar(v.Addr(), node)
+	}
+
+	switch key := path[0].(type) {
+	case string:
+		if field, ok := fieldByKey(v, key); ok {
+			return setScalar(field, path[1:], node)
+		}
+	case int:
+		if key < v.Len() {
+			return setScalar(v.Index(key), path[1:], node)
+		}
+	case ast.MapKeyNode:
+		mapKey := reflect.New(v.Type().Key())
+		if err := yaml.NodeToValue(key, mapKey.Interface()); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+
+		// map values are not addressable
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(mapKey.Elem()); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setScalar(elem, path[1:], node); err != nil {
+			return err
+		}
+		v.SetMapIndex(mapKey.Elem(), elem)
+	}
+	return nil
+}
+
+// unmarshalScalar decodes the given node into the scalar the given pointer points to.
+func unmarshalScalar(ptr reflect.Value, node ast.Node) error {
+	text, _, err := scalarText(node)
+	s, isScalar := ptr.Interface().(Scalar)
+	st, isWellKnown := scalarTypes[ptr.Type().Elem()]
+	if u, ok := ptr.Interface().(yaml.BytesUnmarshaler); ok && !isScalar && !isWellKnown {
+		// the content is not limited to single values
+		return u.UnmarshalYAML([]byte(node.String()))
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case isScalar:
+		return s.UnmarshalScalar(text)
+	case isWellKnown:
+		return st.parse(ptr.Interface(), text)
+	default:
+		return ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+}
+
+// getScalar returns the custom scalar which is behind the given path of the given value.
+func getScalar(v reflect.Value, path []any) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if len(path) == 0 {
+		return v, true
+	}
+
+	switch key := path[0].(type) {
+	case string:
+		if field, ok := fieldByKey(v, key); ok {
+			return getScalar(field, path[1:])
+		}
+	case int:
+		if key < v.Len() {
+			return getScalar(v.Index(key), path[1:])
+		}
+	case ast.MapKeyNode:
+		mapKey := reflect.New(v.Type().Key())
+		if err := yaml.NodeToValue(key, mapKey.Interface()); err != nil {
+			return reflect.Value{}, false
+		}
+		if elem := v.MapIndex(mapKey.Elem()); elem.IsValid() {
+			return getScalar(elem, path[1:])
+		}
+	}
+	return reflect.Value{}, false
+}
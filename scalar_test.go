@@ -0,0 +1,409 @@
+package yacl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type scalarConfig struct {
+	Timeout  time.Duration            `yaml:"timeout" usage:"The timeout"`
+	Since    time.Time                `yaml:"since"`
+	IP       net.IP                   `yaml:"ip"`
+	Network  net.IPNet                `yaml:"network"`
+	Endpoint *url.URL                 `yaml:"endpoint"`
+	Pattern  regexp.Regexp            `yaml:"pattern"`
+	Big      big.Int                  `yaml:"big"`
+	Mode     os.FileMode              `yaml:"mode"`
+	Retries  []time.Duration          `yaml:"retries"`
+	Hosts    map[string]net.IP        `yaml:"hosts"`
+	Limits   map[string]time.Duration `yaml:"limits"`
+	Allowed  []net.IPNet              `yaml:"allowed"`
+}
+
+func newScalarConfig() scalarConfig {
+	endpoint, _ := url.Parse("https://example.com/api?key=value")
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	return scalarConfig{
+		Timeout:  30 * time.Second,
+		Since:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		IP:       net.ParseIP("192.168.0.1"),
+		Network:  *network,
+		Endpoint: endpoint,
+		Pattern:  *regexp.MustCompile("^a+b$"),
+		Big:      *bigInt,
+		Mode:     0644,
+		Retries:  []time.Duration{time.Second, time.Minute},
+		Hosts:    map[string]net.IP{"local": net.ParseIP("127.0.0.1")},
+		Limits:   map[string]time.Duration{"read": time.Hour},
+		Allowed:  []net.IPNet{*network},
+	}
+}
+
+func TestConfig_Scalar_ParseArguments(t *testing.T) {
+	c := scalarConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments(
+		"--timeout=30s",
+		"--since=2025-01-01T00:00:00Z",
+		"--ip=192.168.0.1",
+		"--network=10.0.0.0/8",
+		"--endpoint=https://example.com/api?key=value",
+		"--pattern=^a+b$",
+		"--big=123456789012345678901234567890",
+		"--mode=0644",
+		"--retries=1s",
+		"--retries=1m",
+		"--hosts[local]=127.0.0.1",
+		"--limits[read]=1h",
+		"--allowed=10.0.0.0/8",
+	))
+	assert.Equal(t, newScalarConfig(), c)
+}
+
+func TestConfig_Scalar_ParseYaml(t *testing.T) {
+	c := scalarConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseYaml(bytes.NewBufferString(`
+timeout: 30s
+since: 2025-01-01
+mode: 0644
+`)))
+	assert.Equal(t, 30*time.Second, c.Timeout)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), c.Since)
+	assert.Equal(t, os.FileMode(0644), c.Mode)
+
+	assert.ErrorContains(t, toTest.ParseYaml(bytes.NewBufferString(`network: 10.0.0.0`)), "invalid CIDR address")
+}
+
+func TestConfig_Scalar_Errors(t *testing.T) {
+	c := scalarConfig{}
+	toTest := NewConfig(&c)
+
+	err := toTest.ParseYaml(bytes.NewBufferString(`
+timeout: 30s
+limits:
+  read: 1x
+`))
+	assert.EqualError(t, err, `[4:9] time: unknown unit "x" in duration "1x"`)
+
+	err = toTest.ParseArguments("--timeout=30s", "--hosts[local]=unknown")
+	assert.EqualError(t, err, `--hosts[local] invalid IP address: unknown`)
+
+	var fieldErr FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "--hosts[local]", fieldErr.Flag)
+
+	err = toTest.ParseEnvironment("CFG_0=--mode=0644", "CFG_1=--network=10.0.0.0")
+	assert.EqualError(t, err, `--network invalid CIDR address: 10.0.0.0`)
+
+	err = NewConfig(&c, WithEnvMode(EnvModeNamed)).ParseEnvironment("CFG_TIMEOUT=soon")
+	assert.EqualError(t, err, `--timeout time: invalid duration "soon"`)
+}
+
+func TestConfig_Scalar_HelpFlags(t *testing.T) {
+	c := scalarConfig{}
+	toTest := NewConfig(&c)
+
+	assert.Equal(t, `  --timeout=duration
+  	The timeout
+  --since=time
+  --ip=ip
+  --network=cidr
+  --endpoint=url
+  --pattern=regexp
+  --big=bigint
+  --mode=filemode
+  --retries=[]duration
+  --hosts[string]=ip
+  --limits[string]=duration
+  --allowed=[]cidr
+`, toTest.HelpFlags())
+}
+
+func TestConfig_Scalar_HelpFlags_Default(t *testing.T) {
+	c := scalarConfig{}
+	toTest := NewConfig(&c, WithDefaults(func(s *scalarConfig) {
+		s.Timeout = time.Minute
+		s.Mode = 0600
+	}))
+
+	assert.Contains(t, toTest.HelpFlags(), `  --timeout=duration
+  	The timeout
+  	Default: 1m0s
+`)
+	assert.Contains(t, toTest.HelpFlags(), `  --mode=filemode
+  	Default: 0600
+`)
+}
+
+func TestConfig_Scalar_DumpArgs(t *testing.T) {
+	c := newScalarConfig()
+
+	args := NewConfig(&c).DumpArgs()
+	assert.Equal(t, []string{
+		"--timeout=30s",
+		"--since=2025-01-01T00:00:00Z",
+		"--ip=192.168.0.1",
+		"--network=10.0.0.0/8",
+		"--endpoint=https://example.com/api?key=value",
+		"--pattern=^a+b$",
+		"--big=123456789012345678901234567890",
+		"--mode=0644",
+		"--retries[0]=1s",
+		"--retries[1]=1m0s",
+		"--hosts[local]=127.0.0.1",
+		"--limits[read]=1h0m0s",
+		"--allowed[0]=10.0.0.0/8",
+	}, args)
+
+	parsed := scalarConfig{}
+	assert.NoError(t, NewConfig(&parsed).ParseArguments(args...))
+	assert.Equal(t, c, parsed)
+}
+
+func TestConfig_Scalar_DumpYaml(t *testing.T) {
+	c := newScalarConfig()
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewConfig(&c).DumpYaml(&buf))
+
+	parsed := scalarConfig{}
+	assert.NoError(t, NewConfig(&parsed).ParseYaml(&buf))
+	assert.Equal(t, c, parsed)
+}
+
+// logLevel is a custom scalar which implements the Scalar interface.
+type logLevel struct {
+	level int
+}
+
+var logLevels = []string{"debug", "info", "warn"}
+
+func (l *logLevel) UnmarshalScalar(text string) error {
+	for i, name := range logLevels {
+		if name == text {
+			l.level = i
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level %q", text)
+}
+
+func (l *logLevel) MarshalScalar() (string, error) {
+	return logLevels[l.level], nil
+}
+
+// hostPort is a custom scalar which implements encoding.TextUnmarshaler.
+type hostPort struct {
+	Host string
+	Port int
+}
+
+func (h *hostPort) UnmarshalText(text []byte) error {
+	host, port, err := net.SplitHostPort(string(text))
+	if err != nil {
+		return err
+	}
+	h.Host = host
+	h.Port, err = strconv.Atoi(port)
+	return err
+}
+
+func (h hostPort) MarshalText() ([]byte, error) {
+	return []byte(net.JoinHostPort(h.Host, strconv.Itoa(h.Port))), nil
+}
+
+type customScalarConfig struct {
+	Level    logLevel            `yaml:"level" type:"level" usage:"The log level"`
+	LevelP   *logLevel           `yaml:"levelP"`
+	Levels   map[string]logLevel `yaml:"levels"`
+	Address  hostPort            `yaml:"address"`
+	Backends []hostPort          `yaml:"backends"`
+	Inner    struct {
+		Level logLevel `yaml:"level"`
+	} `yaml:"inner"`
+}
+
+func TestConfig_CustomScalar_ParseArguments(t *testing.T) {
+	c := customScalarConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments(
+		"--level=warn",
+		"--levelP=info",
+		"--levels[db]=debug",
+		"--address=localhost:8080",
+		"--backends=10.0.0.1:80",
+		"--backends=10.0.0.2:80",
+		"--inner.level=info",
+	))
+
+	expected := customScalarConfig{
+		Level:    logLevel{level: 2},
+		LevelP:   &logLevel{level: 1},
+		Levels:   map[string]logLevel{"db": {level: 0}},
+		Address:  hostPort{Host: "localhost", Port: 8080},
+		Backends: []hostPort{{Host: "10.0.0.1", Port: 80}, {Host: "10.0.0.2", Port: 80}},
+	}
+	expected.Inner.Level = logLevel{level: 1}
+	assert.Equal(t, expected, c)
+	o, ok := toTest.Origin("--level")
+	assert.True(t, ok)
+	assert.Equal(t, Origin{Kind: OriginArgument, Index: 0}, o)
+}
+
+func TestConfig_CustomScalar_ParseYaml_Error(t *testing.T) {
+	c := customScalarConfig{}
+	toTest := NewConfig(&c)
+
+	err := toTest.ParseYaml(bytes.NewBufferString(`
+address: localhost:80
+level: unknown
+`))
+	assert.EqualError(t, err, `[3:8] unknown log level "unknown"`)
+
+	err = toTest.ParseYaml(bytes.NewBufferString(`
+level:
+  name: debug
+`))
+	assert.EqualError(t, err, `[3:7] expected a single value but got Mapping`)
+}
+
+func TestConfig_CustomScalar_ParseArguments_Error(t *testing.T) {
+	c := customScalarConfig{}
+	toTest := NewConfig(&c)
+
+	err := toTest.ParseArguments("--address=localhost:80", "--level=unknown")
+	assert.EqualError(t, err, `--level unknown log level "unknown"`)
+
+	var fieldErr FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "--level", fieldErr.Flag)
+
+	err = toTest.ParseArguments("--levels[db]=unknown")
+	assert.EqualError(t, err, `--levels[db] unknown log level "unknown"`)
+
+	err = toTest.ParseEnvironment("CFG_0=--inner.level=info", "CFG_1=--levelP=unknown")
+	assert.EqualError(t, err, `--levelP unknown log level "unknown"`)
+}
+
+func TestConfig_CustomScalar_Unmarshaler_Error(t *testing.T) {
+	c := customScalarConfig{}
+	toTest := NewConfig(&c)
+
+	err := toTest.ParseYaml(bytes.NewBufferString(`
+level: info
+address: localhost
+`))
+	assert.EqualError(t, err, `[3:10] address localhost: missing port in address`)
+
+	err = toTest.ParseArguments("--level=info", "--backends=10.0.0.1:80", "--backends=10.0.0.2")
+	assert.EqualError(t, err, `--backends[1] address 10.0.0.2: missing port in address`)
+
+	var fieldErr FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "--backends[1]", fieldErr.Flag)
+
+	err = toTest.ParseEnvironment("CFG_0=--address=localhost:http")
+	assert.EqualError(t, err, `--address strconv.Atoi: parsing "http": invalid syntax`)
+
+	p := struct {
+		Ports portRange `yaml:"ports"`
+	}{}
+	err = NewConfig(&p).ParseArguments("--ports=8000")
+	assert.EqualError(t, err, `--ports unexpected EOF`)
+}
+
+func TestConfig_CustomScalar_HelpFlags(t *testing.T) {
+	c := customScalarConfig{}
+	toTest := NewConfig(&c)
+
+	assert.Equal(t, `  --level=level
+  	The log level
+  --levelP=yacl.logLevel
+  --levels[string]=yacl.logLevel
+  --address=yacl.hostPort
+  --backends=[]yacl.hostPort
+  --inner.level=yacl.logLevel
+`, toTest.HelpFlags())
+}
+
+func TestConfig_CustomScalar_Dump(t *testing.T) {
+	c := customScalarConfig{
+		Level:    logLevel{level: 2},
+		Levels:   map[string]logLevel{"db": {level: 1}},
+		Address:  hostPort{Host: "localhost", Port: 8080},
+		Backends: []hostPort{{Host: "10.0.0.1", Port: 80}},
+	}
+	toTest := NewConfig(&c)
+
+	args := toTest.DumpArgs()
+	assert.Equal(t, []string{
+		"--level=warn",
+		"--levels[db]=info",
+		"--address=localhost:8080",
+		"--backends[0]=10.0.0.1:80",
+		"--inner.level=debug",
+	}, args)
+
+	parsed := customScalarConfig{}
+	assert.NoError(t, NewConfig(&parsed).ParseArguments(args...))
+	assert.Equal(t, c, parsed)
+
+	var buf bytes.Buffer
+	assert.NoError(t, toTest.DumpYaml(&buf))
+	assert.Equal(t, `level: warn
+levelP: null
+levels:
+  db: info
+address: localhost:8080
+backends:
+- 10.0.0.1:80
+inner:
+  level: debug
+`, buf.String())
+
+	parsed = customScalarConfig{}
+	assert.NoError(t, NewConfig(&parsed).ParseYaml(&buf))
+	assert.Equal(t, c, parsed)
+}
+
+// portRange is a custom scalar which implements yaml.BytesUnmarshaler.
+type portRange struct {
+	From, To int
+}
+
+func (p *portRange) UnmarshalYAML(data []byte) error {
+	var text string
+	if err := yaml.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	_, err := fmt.Sscanf(text, "%d-%d", &p.From, &p.To)
+	return err
+}
+
+func TestConfig_CustomScalar_BytesUnmarshaler(t *testing.T) {
+	c := struct {
+		Ports portRange `yaml:"ports"`
+	}{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments("--ports=8000-8080"))
+	assert.Equal(t, portRange{From: 8000, To: 8080}, c.Ports)
+	assert.Equal(t, "  --ports=yacl.portRange\n", toTest.HelpFlags())
+}
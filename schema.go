@@ -0,0 +1,335 @@
+package yacl
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonSchemaDraft is the JSON Schema draft of the generated schemas (see Config.JSONSchema).
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema (draft 2020-12) of the destination struct. It can be used by IDEs to
+// autocomplete and validate yaml files. The properties are named by the yaml keys, nested structs are objects,
+// slices are arrays and maps are objects with additionalProperties. The usage is used as description, the default
+// values (see DefaultSetter and WithDefaults) as default. The validation rules (see Config.Validate) are translated
+// into constraints. Required fields (see WithRequired and WithRequiredTag) are required in the schema too, except
+// the fields which are usually provided by other sources: positional arguments, named environment variables (see
+// WithEnvTag), secrets, fields with default values and fields of subcommands. Pointer fields are optional. Named
+// structs are defined once in "$defs" (named by their package path), so recursive types are supported. In strict
+// mode (see WithStrict), additional properties are not allowed.
+func (c *Config) JSONSchema() ([]byte, error) {
+	t := reflect.TypeOf(c.dest)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g := schemaGenerator{
+		config:   c,
+		root:     t,
+		required: c.requiredInYaml(),
+		defs:     map[string]map[string]any{},
+	}
+
+	schema := g.structSchema(t, []string{})
+	schema["$schema"] = jsonSchemaDraft
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+type schemaGenerator struct {
+	config *Config
+	root   reflect.Type
+	// required contains the paths (yaml keys) of the fields which are required in yaml files
+	required [][]string
+	defs     map[string]map[string]any
+}
+
+// requiredInYaml returns the paths (yaml keys) of the required fields which have to be present in yaml files. Fields
+// which are usually provided by other sources are not required there.
+func (c *Config) requiredInYaml() [][]string {
+	var paths [][]string
+	for _, info := range c.collectInfos().fi {
+		if !info.required && !hasRule(info.field.Tag.Get(c.options.validateTag), "required") {
+			continue
+		}
+		if info.positional != "" || info.field.Tag.Get(c.options.envTag) != "" || info.Secret() ||
+			info.defaultValue != nil || info.inCommand() {
+			continue
+		}
+
+		keys := make([]string, len(info.path))
+		for i, node := range info.path {
+			keys[i] = node.key
+		}
+		paths = append(paths, keys)
+	}
+	return paths
+}
+
+// isRequired checks if the field of the given path is required in yaml files.
+func (g *schemaGenerator) isRequired(path []string) bool {
+	return path != nil && slices.ContainsFunc(g.required, func(required []string) bool {
+		return slices.Equal(required, path)
+	})
+}
+
+// containsRequired checks if there are required fields below the given path.
+func (g *schemaGenerator) containsRequired(path []string) bool {
+	return path != nil && slices.ContainsFunc(g.required, func(required []string) bool {
+		return len(required) > len(path) && slices.Equal(required[:len(path)], path)
+	})
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	fileModeType = reflect.TypeOf(os.FileMode(0))
+)
+
+// typeSchema returns the schema of the given type. The path contains the yaml keys of the value; it is nil inside
+// the definitions of named structs, because they are shared by all paths.
+func (g *schemaGenerator) typeSchema(t reflect.Type, path []string) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		return map[string]any{"type": "string", "description": "duration (e.g. 1h30m)"}
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == fileModeType:
+		// octal numbers (e.g. 0644) are integers in yaml
+		return map[string]any{"type": []string{"integer", "string"}}
+	case isSecretType(t):
+		return g.typeSchema(t.Field(0).Type, path)
+	case isScalarType(t):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.typeSchema(t.Elem(), path)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.typeSchema(t.Elem(), path)}
+	case reflect.Struct:
+		if t == g.root {
+			return map[string]any{"$ref": "#"}
+		}
+		if t.Name() == "" || g.containsRequired(path) {
+			// anonymous structs can not be recursive and the required fields depend on the path
+			return g.structSchema(t, path)
+		}
+
+		name := t.Name()
+		if t.PkgPath() != "" {
+			name = t.PkgPath() + "." + name
+		}
+		if _, ok := g.defs[name]; !ok {
+			// register the definition before it is generated: recursive references will find it
+			g.defs[name] = map[string]any{}
+			for key, value := range g.structSchema(t, nil) {
+				g.defs[name][key] = value
+			}
+		}
+		return map[string]any{"$ref": "#/$defs/" + jsonPointerEscaper.Replace(name)}
+	default:
+		// interfaces can be anything
+		return map[string]any{}
+	}
+}
+
+// jsonPointerEscaper escapes the reference tokens of JSON pointers (e.g. the slashes of package paths).
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// structSchema returns the schema of the given struct type.
+func (g *schemaGenerator) structSchema(t reflect.Type, path []string) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	g.collectProperties(t, path, properties, &required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	if g.config.options.strict {
+		schema["additionalProperties"] = false
+	}
+	return schema
+}
+
+func (g *schemaGenerator) collectProperties(t reflect.Type, path []string, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		yamlTag := field.Tag.Get("yaml")
+		if yamlTag == "" || yamlTag == "-" {
+			continue
+		}
+
+		key := strings.Split(yamlTag, ",")[0]
+		if key == "" {
+			// inline struct
+			inline := field.Type
+			if inline.Kind() == reflect.Ptr {
+				inline = inline.Elem()
+			}
+			if inline.Kind() == reflect.Struct {
+				g.collectProperties(inline, path, properties, required)
+			}
+			continue
+		}
+
+		var fieldPath []string
+		if path != nil {
+			fieldPath = append(slices.Clip(path), key)
+		}
+
+		schema := g.typeSchema(field.Type, fieldPath)
+		if _, isRef := schema["$ref"]; isRef {
+			// keep the reference untouched, the annotations belong to the property
+			schema = map[string]any{"allOf": []any{schema}}
+		}
+
+		if usage := g.config.getUsage(t, field); usage != "" {
+			schema["description"] = usage
+		}
+		if isLeafKind(field.Type.Kind()) && field.Tag.Get(g.config.options.secretTag) != "true" {
+			if defValue, ok := g.config.getDefaultValue(t, field); ok {
+				schema["default"] = schemaValue(defValue)
+			}
+		}
+
+		g.applyRules(schema, field.Type, field.Tag.Get(g.config.options.validateTag))
+
+		if g.isRequired(fieldPath) {
+			*required = append(*required, key)
+		}
+
+		properties[key] = schema
+	}
+}
+
+// isLeafKind checks if values of the given kind are leaves (no structs, slices, maps or pointers).
+func isLeafKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr, reflect.Interface:
+		return false
+	default:
+		return true
+	}
+}
+
+// schemaValue converts the given value into a value which can be encoded as JSON like it is written in yaml.
+func schemaValue(v any) any {
+	if d, ok := v.(time.Duration); ok {
+		return d.String()
+	}
+	return v
+}
+
+// hasRule checks if the given validation rules contain the given rule.
+func hasRule(rules, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if strings.TrimSpace(rule) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules translates the given validation rules (see Config.Validate) into constraints of the given schema.
+func (g *schemaGenerator) applyRules(schema map[string]any, t reflect.Type, rules string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isSecretType(t) {
+		t = t.Field(0).Type
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch name {
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil || t == durationType {
+				// durations are strings in yaml
+				continue
+			}
+
+			var keyword string
+			switch t.Kind() {
+			case reflect.String:
+				keyword = "Length"
+			case reflect.Slice, reflect.Array:
+				keyword = "Items"
+			case reflect.Map:
+				keyword = "Properties"
+			default:
+				if name == "min" {
+					schema["minimum"] = limit
+				} else {
+					schema["maximum"] = limit
+				}
+				continue
+			}
+			schema[name+keyword] = int(limit)
+		case "oneof":
+			var values []any
+			for _, value := range strings.Split(arg, "|") {
+				values = append(values, enumValue(t, value))
+			}
+			schema["enum"] = values
+		}
+	}
+}
+
+// enumValue converts the given value of the oneof rule into the type of the field.
+func enumValue(t reflect.Type, value string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t != durationType {
+			if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return i
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
@@ -0,0 +1,215 @@
+package yacl
+
+import (
+	"github.com/stretchr/testify/assert"
+	"image"
+	"testing"
+	"time"
+)
+
+type schemaConfig struct {
+	Name     string          `yaml:"name" usage:"The name" validate:"required,min=3,max=10"`
+	Level    string          `yaml:"level" validate:"oneof=debug|info"`
+	Workers  int             `yaml:"workers" validate:"min=1,max=8"`
+	Timeout  *time.Duration  `yaml:"timeout"`
+	Tags     []string        `yaml:"tags" validate:"max=3"`
+	Labels   map[string]uint `yaml:"labels"`
+	Server   schemaServer    `yaml:"server"`
+	Children []schemaConfig  `yaml:"children"`
+	Token    Secret[string]  `yaml:"token"`
+	Any      any             `yaml:"any"`
+	Inline   schemaInline    `yaml:",inline"`
+	ignored  string          `yaml:"ignored"`
+	Untagged string
+	Options  struct {
+		Debug bool `yaml:"debug"`
+	} `yaml:"options"`
+}
+
+type schemaInline struct {
+	Inlined float64 `yaml:"inlined" required:"true"`
+}
+
+type schemaServer struct {
+	Port     int           `yaml:"port" usage:"The port"`
+	Password string        `yaml:"password" secret:"true"`
+	Wait     time.Duration `yaml:"wait"`
+	Parent   *schemaServer `yaml:"parent"`
+}
+
+func (s *schemaServer) SetDefaults() {
+	s.Port = 8080
+	s.Password = "secret"
+	s.Wait = time.Second
+}
+
+func TestConfig_JSONSchema(t *testing.T) {
+	c := schemaConfig{}
+	toTest := NewConfig(&c)
+
+	schema, err := toTest.JSONSchema()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "name": {"type": "string", "description": "The name", "minLength": 3, "maxLength": 10},
+    "level": {"type": "string", "enum": ["debug", "info"]},
+    "workers": {"type": "integer", "minimum": 1, "maximum": 8},
+    "timeout": {"type": "string", "description": "duration (e.g. 1h30m)"},
+    "tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3},
+    "labels": {"type": "object", "additionalProperties": {"type": "integer", "minimum": 0}},
+    "server": {"allOf": [{"$ref": "#/$defs/github.com~1rainu~1go-yacl.schemaServer"}]},
+    "children": {"type": "array", "items": {"$ref": "#"}},
+    "token": {"type": "string"},
+    "any": {},
+    "inlined": {"type": "number"},
+    "options": {"type": "object", "properties": {"debug": {"type": "boolean"}}}
+  },
+  "required": ["name", "inlined"],
+  "$defs": {
+    "github.com/rainu/go-yacl.schemaServer": {
+      "type": "object",
+      "properties": {
+        "port": {"type": "integer", "description": "The port", "default": 8080},
+        "password": {"type": "string"},
+        "wait": {"type": "string", "description": "duration (e.g. 1h30m)", "default": "1s"},
+        "parent": {"allOf": [{"$ref": "#/$defs/github.com~1rainu~1go-yacl.schemaServer"}]}
+      }
+    }
+  }
+}`, string(schema))
+}
+
+func TestConfig_JSONSchema_Strict(t *testing.T) {
+	c := struct {
+		Server struct {
+			Port int `yaml:"port"`
+		} `yaml:"server"`
+	}{}
+	toTest := NewConfig(&c, WithStrict(true))
+
+	schema, err := toTest.JSONSchema()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "server": {
+      "type": "object",
+      "properties": {"port": {"type": "integer"}},
+      "additionalProperties": false
+    }
+  },
+  "additionalProperties": false
+}`, string(schema))
+}
+
+func TestConfig_JSONSchema_Enum(t *testing.T) {
+	c := struct {
+		Port  int     `yaml:"port" validate:"oneof=80|443"`
+		Ratio float64 `yaml:"ratio" validate:"oneof=0.5|1"`
+	}{}
+	toTest := NewConfig(&c)
+
+	schema, err := toTest.JSONSchema()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "port": {"type": "integer", "enum": [80, 443]},
+    "ratio": {"type": "number", "enum": [0.5, 1]}
+  }
+}`, string(schema))
+}
+
+type schemaEndpoint struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+type schemaRequiredConfig struct {
+	File     string         `yaml:"file" positional:"0" required:"true"`
+	Name     string         `yaml:"name" required:"true"`
+	Level    string         `yaml:"level" validate:"required"`
+	Primary  schemaEndpoint `yaml:"primary"`
+	Backup   schemaEndpoint `yaml:"backup"`
+	Database struct {
+		URL      string `yaml:"url"`
+		User     string `yaml:"user" env:"DB_USER"`
+		Password string `yaml:"password" secret:"true"`
+	} `yaml:"database"`
+	Serve struct {
+		Port int `yaml:"port" required:"true"`
+	} `yaml:"serve" command:"serve"`
+}
+
+func TestConfig_JSONSchema_Required(t *testing.T) {
+	c := schemaRequiredConfig{}
+	toTest := NewConfig(&c,
+		WithDefaults(func(c *schemaRequiredConfig) {
+			c.Level = "info"
+		}),
+		WithRequired("primary.host", "database.url", "database.user", "database.password"),
+	)
+
+	schema, err := toTest.JSONSchema()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "file": {"type": "string"},
+    "name": {"type": "string"},
+    "level": {"type": "string", "default": "info"},
+    "primary": {
+      "type": "object",
+      "properties": {"host": {"type": "string"}, "port": {"type": "integer"}},
+      "required": ["host"]
+    },
+    "backup": {"allOf": [{"$ref": "#/$defs/github.com~1rainu~1go-yacl.schemaEndpoint"}]},
+    "database": {
+      "type": "object",
+      "properties": {"url": {"type": "string"}, "user": {"type": "string"}, "password": {"type": "string"}},
+      "required": ["url"]
+    },
+    "serve": {"type": "object", "properties": {"port": {"type": "integer"}}}
+  },
+  "required": ["name"],
+  "$defs": {
+    "github.com/rainu/go-yacl.schemaEndpoint": {
+      "type": "object",
+      "properties": {"host": {"type": "string"}, "port": {"type": "integer"}}
+    }
+  }
+}`, string(schema))
+}
+
+// Point has the same name like image.Point
+type Point struct {
+	X int `yaml:"x"`
+}
+
+func TestConfig_JSONSchema_SameNames(t *testing.T) {
+	c := struct {
+		Own     Point       `yaml:"own"`
+		Foreign image.Point `yaml:"foreign"`
+	}{}
+	toTest := NewConfig(&c)
+
+	schema, err := toTest.JSONSchema()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "own": {"allOf": [{"$ref": "#/$defs/github.com~1rainu~1go-yacl.Point"}]},
+    "foreign": {"allOf": [{"$ref": "#/$defs/image.Point"}]}
+  },
+  "$defs": {
+    "github.com/rainu/go-yacl.Point": {"type": "object", "properties": {"x": {"type": "integer"}}},
+    "image.Point": {"type": "object", "properties": {}}
+  }
+}`, string(schema))
+}
@@ -0,0 +1,303 @@
+package yacl
+
+import (
+	"fmt"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SecretMask is printed instead of the value of secret fields.
+const SecretMask = "******"
+
+// Secret wraps a value which should never be printed. Fields of this type are treated like fields with the
+// secret tag (see WithSecretTag): help, dumps and error messages will print SecretMask instead of the value.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret which holds the given value.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Get returns the wrapped value.
+func (s Secret[T]) Get() T {
+	return s.value
+}
+
+// Set sets the wrapped value.
+func (s *Secret[T]) Set(value T) {
+	s.value = value
+}
+
+// String returns SecretMask so that the value will not be printed accidentally.
+func (s Secret[T]) String() string {
+	return SecretMask
+}
+
+// GoString returns SecretMask so that the value will not be printed accidentally.
+func (s Secret[T]) GoString() string {
+	return SecretMask
+}
+
+// MarshalYAML returns SecretMask so that the value will not be written accidentally.
+func (s Secret[T]) MarshalYAML() (any, error) {
+	return SecretMask, nil
+}
+
+// MarshalJSON returns SecretMask so that the value will not be written accidentally.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + SecretMask + `"`), nil
+}
+
+// UnmarshalYAML decodes the wrapped value.
+func (s *Secret[T]) UnmarshalYAML(unmarshal func(any) error) error {
+	return unmarshal(&s.value)
+}
+
+func (s Secret[T]) secretValue() any {
+	return s.value
+}
+
+type secretHolder interface {
+	secretValue() any
+}
+
+var secretHolderType = reflect.TypeOf((*secretHolder)(nil)).Elem()
+
+// isSecretType checks if the given type is a Secret.
+func isSecretType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(secretHolderType)
+}
+
+// secretValueType returns the (help) type of the value which is wrapped by the given Secret type.
+func secretValueType(t reflect.Type) string {
+	valueType := t.Field(0).Type
+	if isScalarType(valueType) {
+		return typeName(valueType)
+	}
+	return valueType.Kind().String()
+}
+
+// unwrapSecret returns the wrapped value if the given value is a Secret.
+func unwrapSecret(v reflect.Value) reflect.Value {
+	if v.IsValid() && isSecretType(v.Type()) {
+		return reflect.ValueOf(v.Interface().(secretHolder).secretValue())
+	}
+	return v
+}
+
+// Secret checks if the field is secret: the field itself, or one of its parents, has the secret tag or
+// is of type Secret.
+func (f *fieldInfo) Secret() bool {
+	return slices.ContainsFunc(f.path, func(node *fieldPathNode) bool {
+		return node.secret
+	})
+}
+
+// isSecret checks if the given path segments are addressing a secret field (or a value inside a secret field).
+func (f *fieldInfos) isSecret(segments []string) bool {
+	for i := len(segments); i > 0; i-- {
+		if _, info := f.normalizePath(segments[:i]); info != nil {
+			return info.Secret()
+		}
+	}
+	return false
+}
+
+// maskArgument replaces the value of the given argument with SecretMask if the given (suggested) field is secret.
+func (c *Config) maskArgument(arg string, info *fieldInfo) string {
+	if info == nil || !info.Secret() {
+		return arg
+	}
+	if key, _, ok := strings.Cut(arg, string(c.options.assignSign)); ok {
+		return key + string(c.options.assignSign) + SecretMask
+	}
+	return arg
+}
+
+// maskYaml calls the given function for all value nodes of secret fields inside the given yaml node.
+func (f *fieldInfos) maskYaml(node ast.Node, path []string, mask func(value *ast.Node)) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			f.maskYaml(value, path, mask)
+		}
+	case *ast.MappingValueNode:
+		subPath := append(slices.Clip(path), yamlKey(n.Key))
+		if f.isSecret(subPath) {
+			mask(&n.Value)
+		} else {
+			f.maskYaml(n.Value, subPath, mask)
+		}
+	case *ast.SequenceNode:
+		for i := range n.Values {
+			f.maskYaml(n.Values[i], append(slices.Clip(path), fmt.Sprintf("[%d]", i)), mask)
+		}
+	case *ast.TagNode:
+		f.maskYaml(n.Value, path, mask)
+	case *ast.AnchorNode:
+		f.maskYaml(n.Value, path, mask)
+	}
+}
+
+// secretError hides all secret values inside the message of the wrapped error.
+type secretError struct {
+	message string
+	err     error
+}
+
+func (e *secretError) Error() string {
+	return e.message
+}
+
+func (e *secretError) Unwrap() error {
+	return e.err
+}
+
+// maskError replaces all values of secret fields inside the given yaml node with SecretMask. Because the errors
+// of the yaml decoder print the source lazily, the returned error will not contain the secret values anymore.
+func (f *fieldInfos) maskError(err error, body ast.Node) error {
+	var values []string
+	f.maskYaml(body, nil, func(value *ast.Node) {
+		ast.Walk(secretVisitor(func(n ast.ScalarNode) {
+			tk := n.GetToken()
+			if tk == nil {
+				return
+			}
+			if v := strings.TrimSpace(tk.Value); v != "" {
+				values = append(values, v)
+			}
+			tk.Origin = strings.Replace(tk.Origin, strings.TrimSpace(tk.Origin), SecretMask, 1)
+			tk.Value = SecretMask
+		}), *value)
+	})
+	if len(values) == 0 {
+		return err
+	}
+
+	message := err.Error()
+	for _, value := range values {
+		// the value can also be a part of the message itself (e.g. errors of custom unmarshalers)
+		message = maskMessage(message, value)
+	}
+	return &secretError{message: message, err: err}
+}
+
+// minMaskLength is the minimal length of secret values which are masked inside the text of error messages if they
+// are not quoted. Shorter values would mask arbitrary parts of the message.
+const minMaskLength = 4
+
+// maskMessage replaces the quoted occurrences of the given value and the unquoted occurrences as whole words (if
+// the value is long enough) inside the given message with SecretMask.
+func maskMessage(message, value string) string {
+	message = strings.ReplaceAll(message, strconv.Quote(value), strconv.Quote(SecretMask))
+	for _, quote := range []string{`"`, `'`, "`"} {
+		message = strings.ReplaceAll(message, quote+value+quote, quote+SecretMask+quote)
+	}
+	if len(value) < minMaskLength {
+		return message
+	}
+
+	var sb strings.Builder
+	for {
+		i := strings.Index(message, value)
+		if i < 0 {
+			break
+		}
+		end := i + len(value)
+		if isWordBoundary(message, i-1) && isWordBoundary(message, end) {
+			sb.WriteString(message[:i] + SecretMask)
+		} else {
+			sb.WriteString(message[:end])
+		}
+		message = message[end:]
+	}
+	sb.WriteString(message)
+	return sb.String()
+}
+
+// isWordBoundary checks if the byte at the given index of the message does not belong to a word.
+func isWordBoundary(message string, i int) bool {
+	if i < 0 || i >= len(message) {
+		return true
+	}
+	b := message[i]
+	return !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_' || b >= 0x80)
+}
+
+type secretVisitor func(n ast.ScalarNode)
+
+func (v secretVisitor) Visit(node ast.Node) ast.Visitor {
+	if scalar, ok := node.(ast.ScalarNode); ok {
+		v(scalar)
+	}
+	return v
+}
+
+// maskedYaml returns the given value as yaml. All values of secret fields are replaced with SecretMask.
+func (f *fieldInfos) maskedYaml(value any) ([]byte, error) {
+	content, err := yaml.MarshalWithOptions(value, scalarEncodeOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := parser.ParseBytes(content, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Docs) == 0 || file.Docs[0].Body == nil {
+		return content, nil
+	}
+
+	changed := false
+
+	// the yaml encoder doesn't know custom scalars
+	var scalars []scalarNode
+	findScalarNodes(&file.Docs[0].Body, reflect.TypeOf(value), nil, isCustomScalar, &scalars)
+	for _, sn := range scalars {
+		if v, ok := getScalar(reflect.ValueOf(value), sn.path); ok {
+			st, _ := lookupScalarType(v.Type())
+			if err = replaceNode(sn.node, formatScalar(st, v)); err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+	}
+
+	f.maskYaml(file.Docs[0].Body, nil, func(value *ast.Node) {
+		if err == nil {
+			err = replaceNode(value, SecretMask)
+			changed = true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return content, nil
+	}
+	return []byte(file.String()), nil
+}
+
+// replaceNode replaces the given node with a string node (at the same position) which contains the given text.
+func replaceNode(node *ast.Node, text string) error {
+	n, err := yaml.ValueToNode(text)
+	if err != nil {
+		return err
+	}
+
+	pos := *(*node).GetToken().Position
+	replacement := *n.(*ast.StringNode)
+	token := *replacement.Token
+	token.Position = &pos
+	replacement.Token = &token
+
+	*node = &replacement
+	return nil
+}
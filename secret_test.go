@@ -0,0 +1,158 @@
+package yacl
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type secretConfig struct {
+	User     string         `yaml:"user"`
+	Password string         `yaml:"password" secret:"true" validate:"min=8"`
+	Token    Secret[string] `yaml:"token"`
+	Pin      *Secret[int]   `yaml:"pin" validate:"max=9999"`
+	Database struct {
+		Host string `yaml:"host"`
+	} `yaml:"database" secret:"true"`
+	Keys map[string]string `yaml:"keys" secret:"true"`
+}
+
+func (s *secretConfig) SetDefaults() {
+	if s.Password == "" {
+		s.Password = "default-password"
+	}
+}
+
+func newSecretConfig() secretConfig {
+	c := secretConfig{
+		User:     "admin",
+		Password: "my-password",
+		Token:    NewSecret("my-token"),
+		Pin:      P(NewSecret(1234)),
+		Keys:     map[string]string{"api": "my-api-key"},
+	}
+	c.Database.Host = "my-host"
+	return c
+}
+
+func TestSecret(t *testing.T) {
+	s := NewSecret("value")
+	assert.Equal(t, "value", s.Get())
+	assert.Equal(t, SecretMask, s.String())
+
+	s.Set("changed")
+	assert.Equal(t, "changed", s.Get())
+	assert.NotContains(t, strings.Join([]string{
+		s.String(),
+		s.GoString(),
+	}, ""), "changed")
+}
+
+func TestConfig_Secret_Parse(t *testing.T) {
+	c := secretConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.ParseArguments("--password=my-password", "--token=my-token", "--pin=1234"))
+	assert.Equal(t, "my-password", c.Password)
+	assert.Equal(t, "my-token", c.Token.Get())
+	assert.Equal(t, 1234, c.Pin.Get())
+
+	o, _ := toTest.Origin("token")
+	assert.Equal(t, Origin{Kind: OriginArgument, Index: 1}, o)
+}
+
+func TestConfig_Secret_CollectInfos(t *testing.T) {
+	c := secretConfig{}
+
+	var secrets []string
+	for _, info := range NewConfig(&c).CollectInfos().Infos() {
+		if info.Secret() {
+			secrets = append(secrets, info.Path())
+		}
+	}
+	assert.Equal(t, []string{"password", "token", "pin", "database.host", "keys.[string]"}, secrets)
+}
+
+func TestConfig_Secret_HelpFlags(t *testing.T) {
+	c := secretConfig{}
+	help := NewConfig(&c).HelpFlags()
+
+	assert.Contains(t, help, "--token=string\n")
+	assert.Contains(t, help, "--pin=int\n")
+	assert.Contains(t, help, "Default: "+SecretMask)
+	assert.NotContains(t, help, "default-password")
+}
+
+func TestConfig_Secret_Dump(t *testing.T) {
+	c := newSecretConfig()
+	toTest := NewConfig(&c)
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, toTest.DumpYaml(&buf))
+	env, err := NewConfig(&c, WithEnvMode(EnvModeNamed)).DumpEnv()
+	assert.NoError(t, err)
+
+	dumps := map[string]string{
+		"yaml": buf.String(),
+		"args": strings.Join(toTest.DumpArgs(), "\n"),
+		"env":  strings.Join(env, "\n"),
+	}
+	for name, dump := range dumps {
+		t.Run(name, func(t *testing.T) {
+			assert.Contains(t, dump, "admin")
+			assert.Contains(t, dump, SecretMask)
+			for _, secret := range []string{"my-password", "my-token", "1234", "my-host", "my-api-key"} {
+				assert.NotContains(t, dump, secret)
+			}
+		})
+	}
+
+	assert.Contains(t, dumps["args"], "--user=admin\n--password="+SecretMask+"\n--token="+SecretMask)
+}
+
+func TestConfig_Secret_Errors(t *testing.T) {
+	c := secretConfig{}
+	toTest := NewConfig(&c)
+
+	err := toTest.ParseYaml(strings.NewReader("user: admin\npin: my-pin\n"))
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "my-pin")
+	assert.Contains(t, err.Error(), SecretMask)
+
+	err = toTest.ParseArguments("--pin=my-pin")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "my-pin")
+
+	// short values are only masked inside the source excerpt
+	err = toTest.ParseArguments("--pin=a")
+	assert.Equal(t, "[1:8] cannot unmarshal string into Go struct field secretConfig.Pin of type int\n"+
+		">  1 | \"pin\": "+SecretMask+"\n              ^\n", err.Error())
+
+	err = toTest.ParseArguments("--password=short", "--pin=12345")
+	assert.EqualError(t, err, "invalid values: --password must have a length of at least 8, --pin must be at most 9999")
+
+	err = NewConfig(&c, WithStrict(true)).ParseArguments("--passwort=my-password")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "my-password")
+	assert.Contains(t, err.Error(), "--passwort="+SecretMask)
+}
+
+func TestMaskMessage(t *testing.T) {
+	tests := []struct {
+		message  string
+		value    string
+		expected string
+	}{
+		{message: `invalid value "a" for a field`, value: "a", expected: `invalid value "******" for a field`},
+		{message: `invalid value a for a field`, value: "a", expected: `invalid value a for a field`},
+		{message: `invalid value 'my-pin'`, value: "my-pin", expected: `invalid value '******'`},
+		{message: `invalid value "line\nbreak"`, value: "line\nbreak", expected: `invalid value "******"`},
+		{message: `invalid value my-pin: my-pins`, value: "my-pin", expected: `invalid value ******: my-pins`},
+	}
+	for _, test := range tests {
+		t.Run(test.message, func(t *testing.T) {
+			assert.Equal(t, test.expected, maskMessage(test.message, test.value))
+		})
+	}
+}
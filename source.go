@@ -0,0 +1,349 @@
+package yacl
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+)
+
+// Precedence defines the order in which the sources are applied by Config.Load. Sources with a higher
+// precedence are applied later and therefore override the values of sources with a lower precedence.
+type Precedence int
+
+const (
+	PrecedenceDefaults Precedence = iota
+	PrecedenceFile
+	PrecedenceEnvironment
+	PrecedenceArguments
+)
+
+// Source is a source of configuration values which can be loaded by Config.Load.
+type Source interface {
+	// Name returns a human-readable name of the source. It is used in error messages.
+	Name() string
+
+	// Precedence returns the precedence of the source.
+	Precedence() Precedence
+
+	// Reader returns a reader which delivers the content of the source in yaml-format.
+	Reader(c *Config) (io.ReadCloser, error)
+}
+
+type source struct {
+	name       string
+	precedence Precedence
+	reader     func(c *Config) (io.ReadCloser, error)
+
+	// profiles returns the profiles which are selected by the source (nil if the source selects no profiles)
+	profiles func(c *Config) []string
+}
+
+func (s *source) Name() string {
+	return s.name
+}
+
+func (s *source) Precedence() Precedence {
+	return s.precedence
+}
+
+func (s *source) Reader(c *Config) (io.ReadCloser, error) {
+	return s.reader(c)
+}
+
+// namedReader is a reader which knows the name of its origin (like os.File).
+type namedReader struct {
+	io.Reader
+	name string
+	kind OriginKind
+}
+
+func (n *namedReader) Name() string {
+	return n.name
+}
+
+func (n *namedReader) Close() error {
+	return nil
+}
+
+// FileSource creates a source which reads the given file. The format (yaml, JSON or TOML) is detected by the
+// extension of the file (see Config.ParseFile).
+func FileSource(path string) Source {
+	return &source{
+		name:       path,
+		precedence: PrecedenceFile,
+		reader: func(*Config) (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+	}
+}
+
+// FSSource creates a source which reads all files of the given file system which match the given pattern
+// (see Config.ParseFS). The files are applied in lexical order. To use an embedded configuration (embed.FS) as
+// defaults, the precedence can be changed:
+//
+//	WithPrecedence(FSSource(embedded, "defaults/*.yaml"), PrecedenceDefaults)
+func FSSource(fsys fs.FS, pattern string) Source {
+	return &fsSource{fsys: fsys, pattern: pattern}
+}
+
+type fsSource struct {
+	fsys    fs.FS
+	pattern string
+}
+
+func (f *fsSource) Name() string {
+	return f.pattern
+}
+
+func (f *fsSource) Precedence() Precedence {
+	return PrecedenceFile
+}
+
+// Reader returns a reader of the matching file. If multiple files match, an error is returned: they can only be
+// applied by Config.Load (or Config.ParseFS).
+func (f *fsSource) Reader(c *Config) (io.ReadCloser, error) {
+	sources, err := f.sources(c)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(sources) {
+	case 0:
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	case 1:
+		return sources[0].Reader(c)
+	default:
+		return nil, fmt.Errorf("%d files match the pattern", len(sources))
+	}
+}
+
+func (f *fsSource) sources(*Config) ([]Source, error) {
+	return fsSources(f.fsys, f.pattern)
+}
+
+// fsSources returns a source for each (regular) file of the given file system which matches the given pattern.
+// The sources are sorted by the name of the files.
+func fsSources(fsys fs.FS, pattern string) ([]Source, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+
+	var sources []Source
+	for _, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		sources = append(sources, &source{
+			name:       name,
+			precedence: PrecedenceFile,
+			reader: func(*Config) (io.ReadCloser, error) {
+				file, err := fsys.Open(name)
+				if err != nil {
+					return nil, err
+				}
+				return &fsFile{File: file, name: name, fsys: fsys}, nil
+			},
+		})
+	}
+	return sources, nil
+}
+
+// fsFile is a file of a fs.FS which knows its name (like os.File).
+type fsFile struct {
+	fs.File
+	name string
+	fsys fs.FS
+}
+
+func (f *fsFile) Name() string {
+	return f.name
+}
+
+// EnvironmentSource creates a source which reads the given environment variables (see Config.EnvironmentReader).
+func EnvironmentSource(env ...string) Source {
+	return environmentSource(func() []string { return env })
+}
+
+// OsEnvironmentSource creates a source which reads the environment variables (os.Environ()).
+func OsEnvironmentSource() Source {
+	return environmentSource(os.Environ)
+}
+
+func environmentSource(env func() []string) Source {
+	return &source{
+		name:       "environment",
+		precedence: PrecedenceEnvironment,
+		profiles: func(c *Config) []string {
+			return c.profilesOfEnv(env())
+		},
+		reader: func(c *Config) (io.ReadCloser, error) {
+			reader := c.EnvironmentReader(env()...)
+			if reader == nil {
+				// no environment prefix is configured
+				return io.NopCloser(bytes.NewReader(nil)), nil
+			}
+			return reader, nil
+		},
+	}
+}
+
+// ArgumentSource creates a source which reads the given arguments (see Config.ArgumentReader).
+func ArgumentSource(args ...string) Source {
+	return &source{
+		name:       "arguments",
+		precedence: PrecedenceArguments,
+		profiles: func(c *Config) []string {
+			return c.profilesOfArgs(args)
+		},
+		reader: func(c *Config) (io.ReadCloser, error) {
+			return c.ArgumentReader(args...), nil
+		},
+	}
+}
+
+// OsArgumentSource creates a source which reads the command line arguments (os.Args[1:]).
+func OsArgumentSource() Source {
+	return ArgumentSource(os.Args[1:]...)
+}
+
+// MapSource creates a source which delivers the given values. The keys of the map are the yaml keys
+// of the destination struct. The values can be nested maps or slices. By default, the source has the
+// precedence of defaults, use WithPrecedence to change it.
+func MapSource(name string, values map[string]any) Source {
+	return &source{
+		name:       name,
+		precedence: PrecedenceDefaults,
+		reader: func(*Config) (io.ReadCloser, error) {
+			content, err := yaml.Marshal(values)
+			if err != nil {
+				return nil, err
+			}
+			return &namedReader{Reader: bytes.NewReader(content), name: name, kind: OriginMap}, nil
+		},
+	}
+}
+
+type precedenceSource struct {
+	Source
+	precedence Precedence
+}
+
+func (p *precedenceSource) Precedence() Precedence {
+	return p.precedence
+}
+
+// WithPrecedence returns a source which behaves like the given one, but has the given precedence.
+func WithPrecedence(s Source, precedence Precedence) Source {
+	return &precedenceSource{Source: s, precedence: precedence}
+}
+
+// Load applies the given sources in order of their precedence (defaults < files < environment < arguments)
+// and sets the values in the destination struct. Sources with the same precedence are applied in the given order.
+// After all sources are applied, the default values are applied exactly once (see WithAutoApplyDefaults),
+// the references are resolved (see Config.Resolve), the required fields are checked (see Config.CheckRequired)
+// and the values are validated (see WithAutoValidate). The errors of all sources (or of these steps) are collected
+// and returned as one error.
+func (c *Config) Load(sources ...Source) error {
+	c.sources = slices.Clone(sources)
+	c.loaded = true
+	c.profileLayers = nil
+	c.pathFlags = pathFlags(sources)
+
+	sorted := slices.Clone(sources)
+	slices.SortStableFunc(sorted, func(a, b Source) int {
+		return cmp.Compare(a.Precedence(), b.Precedence())
+	})
+
+	if c.options.profiles {
+		c.profiles = c.selectProfiles(sorted)
+	}
+
+	var errs []error
+	for _, s := range sorted {
+		if err := c.load(s); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+
+	if c.options.autoApplyDefaults {
+		c.ApplyDefaults()
+	}
+	c.applyCommandDefaults()
+
+	if len(errs) == 0 {
+		if err := c.checkValues(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// pathFlags returns the path flags of the given discovery sources (see WithPathFlag).
+func pathFlags(sources []Source) []string {
+	var flags []string
+	for _, s := range sources {
+		if p, ok := s.(*precedenceSource); ok {
+			s = p.Source
+		}
+		if d, ok := s.(*Discovery); ok && d.options.pathFlag != "" {
+			flags = append(flags, d.options.pathFlag)
+		}
+	}
+	return flags
+}
+
+// sourceGroup is a source which consists of multiple sources (for example the files of a Discovery).
+type sourceGroup interface {
+	// sources returns the sources in the order in which they should be applied by the given Config.
+	sources(c *Config) ([]Source, error)
+}
+
+func (c *Config) load(s Source) error {
+	if p, ok := s.(*precedenceSource); ok {
+		// the precedence is only relevant for the order of the sources
+		return c.load(p.Source)
+	}
+	if group, ok := s.(sourceGroup); ok {
+		sources, err := group.sources(c)
+		if err != nil {
+			return err
+		}
+
+		var errs []error
+		for _, sub := range sources {
+			if err := c.load(sub); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sub.Name(), err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	reader, err := s.Reader(c)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if r, ok := reader.(*Reader); ok && r.envNames == nil && len(c.commandList()) > 0 {
+		// the arguments could contain subcommands
+		return c.parseCommandLine(r.args)
+	}
+	if err := c.parse(reader); err != nil {
+		return err
+	}
+	return c.parseProfileOverlays(reader)
+}
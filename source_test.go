@@ -0,0 +1,175 @@
+package yacl
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+type loadConfig struct {
+	File  string `yaml:"file"`
+	Env   string `yaml:"env"`
+	Arg   string `yaml:"arg"`
+	Map   string `yaml:"map"`
+	Inner struct {
+		Value string `yaml:"value"`
+	} `yaml:"inner"`
+
+	defaultsApplied int
+}
+
+func (l *loadConfig) SetDefaults() {
+	l.defaultsApplied++
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestConfig_Load(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+file: file
+env: file
+arg: file
+map: file
+inner:
+  value: file
+`)
+	c := loadConfig{}
+	toTest := NewConfig(&c)
+
+	// the given order should not matter
+	assert.NoError(t, toTest.Load(
+		ArgumentSource("--arg=arg", "--inner.value=arg"),
+		EnvironmentSource("CFG_0=--env=env", "CFG_1=--arg=env"),
+		FileSource(path),
+		MapSource("map", map[string]any{"file": "map", "map": "map"}),
+	))
+
+	assert.Equal(t, "file", c.File)
+	assert.Equal(t, "env", c.Env)
+	assert.Equal(t, "arg", c.Arg)
+	assert.Equal(t, "file", c.Map)
+	assert.Equal(t, "arg", c.Inner.Value)
+	assert.Equal(t, 1, c.defaultsApplied)
+}
+
+func TestConfig_Load_SamePrecedence(t *testing.T) {
+	p1 := writeFile(t, "1.yaml", "file: first\nenv: first")
+	p2 := writeFile(t, "2.yaml", "file: second")
+
+	c := loadConfig{}
+	assert.NoError(t, NewConfig(&c).Load(FileSource(p1), FileSource(p2)))
+
+	assert.Equal(t, "second", c.File)
+	assert.Equal(t, "first", c.Env)
+}
+
+func TestConfig_Load_WithPrecedence(t *testing.T) {
+	c := loadConfig{}
+	assert.NoError(t, NewConfig(&c).Load(
+		WithPrecedence(MapSource("overrides", map[string]any{"arg": "map"}), PrecedenceArguments+1),
+		ArgumentSource("--arg=arg"),
+	))
+
+	assert.Equal(t, "map", c.Arg)
+}
+
+func TestConfig_Load_Empty(t *testing.T) {
+	c := loadConfig{}
+	assert.NoError(t, NewConfig(&c, WithPrefixEnv("")).Load(
+		ArgumentSource(),
+		EnvironmentSource("CFG_0=--env=env"),
+		FileSource(writeFile(t, "empty.yaml", "")),
+	))
+
+	assert.Equal(t, "", c.Env)
+	assert.Equal(t, 1, c.defaultsApplied)
+}
+
+func TestConfig_Load_WithoutAutoDefaults(t *testing.T) {
+	c := loadConfig{}
+	assert.NoError(t, NewConfig(&c, WithAutoApplyDefaults(false)).Load(ArgumentSource("--arg=arg")))
+
+	assert.Equal(t, "arg", c.Arg)
+	assert.Equal(t, 0, c.defaultsApplied)
+}
+
+func TestConfig_Load_AggregatedError(t *testing.T) {
+	c := loadConfig{}
+	err := NewConfig(&c).Load(
+		FileSource(filepath.Join(t.TempDir(), "missing.yaml")),
+		FileSource(writeFile(t, "invalid.yaml", "inner: [")),
+		ArgumentSource("--arg=arg"),
+	)
+
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Contains(t, err.Error(), "missing.yaml")
+	assert.Contains(t, err.Error(), "invalid.yaml")
+
+	// the valid sources should be applied nevertheless
+	assert.Equal(t, "arg", c.Arg)
+	assert.Equal(t, 1, c.defaultsApplied)
+}
+
+func TestConfig_Load_FSSource(t *testing.T) {
+	defaults := fstest.MapFS{
+		"defaults/base.yaml":  {Data: []byte("file: defaults\nenv: defaults\n")},
+		"defaults/inner.yaml": {Data: []byte("inner:\n  value: defaults\n")},
+	}
+	conf := fstest.MapFS{
+		"conf.d/10-file.yaml":  {Data: []byte("file: first\n")},
+		"conf.d/20-file.yaml":  {Data: []byte("file: second\n")},
+		"conf.d/30-empty.yaml": {Data: []byte{}},
+	}
+
+	c := loadConfig{}
+	toTest := NewConfig(&c)
+
+	assert.NoError(t, toTest.Load(
+		FSSource(conf, "conf.d/*.yaml"),
+		WithPrecedence(FSSource(defaults, "defaults/*.yaml"), PrecedenceDefaults),
+	))
+	assert.Equal(t, "second", c.File)
+	assert.Equal(t, "defaults", c.Env)
+	assert.Equal(t, "defaults", c.Inner.Value)
+	assert.Equal(t, 1, c.defaultsApplied)
+
+	o, _ := toTest.Origin("inner.value")
+	assert.Equal(t, Origin{Kind: OriginFile, Name: "defaults/inner.yaml", Line: 2}, o)
+}
+
+func TestConfig_Load_FSSource_Error(t *testing.T) {
+	conf := fstest.MapFS{
+		"conf.d/10-valid.yaml":   {Data: []byte("file: valid\n")},
+		"conf.d/20-invalid.yaml": {Data: []byte("inner: [\n")},
+	}
+
+	c := loadConfig{}
+	err := NewConfig(&c).Load(FSSource(conf, "conf.d/*.yaml"))
+	assert.ErrorContains(t, err, "conf.d/*.yaml: conf.d/20-invalid.yaml: [1:8]")
+	assert.Equal(t, "valid", c.File)
+}
+
+func TestFSSource_Reader(t *testing.T) {
+	conf := fstest.MapFS{
+		"a.yaml": {Data: []byte("file: a\n")},
+		"b.yaml": {Data: []byte("file: b\n")},
+	}
+	c := loadConfig{}
+	config := NewConfig(&c)
+
+	reader, err := FSSource(conf, "a.yaml").Reader(config)
+	assert.NoError(t, err)
+	assert.NoError(t, config.parse(reader))
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, "a", c.File)
+
+	_, err = FSSource(conf, "*.yaml").Reader(config)
+	assert.EqualError(t, err, "2 files match the pattern")
+}
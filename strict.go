@@ -0,0 +1,182 @@
+package yacl
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"slices"
+	"strings"
+)
+
+// UnknownKey describes a key which can not be assigned to any field of the destination struct.
+type UnknownKey struct {
+	// Key is the unknown key. For arguments and environment variables it is the whole argument.
+	Key string
+
+	// Origin is the origin of the unknown key.
+	Origin Origin
+
+	// Suggestion is the most similar known flag (e.g. "--server.port") or empty if there is no similar one.
+	Suggestion string
+}
+
+func (u UnknownKey) String() string {
+	result := fmt.Sprintf("%s (%s)", u.Key, u.Origin)
+	if u.Suggestion != "" {
+		result += fmt.Sprintf(" did you mean %s?", u.Suggestion)
+	}
+	return result
+}
+
+// UnknownKeyError is returned in strict mode (see WithStrict) if there are keys which can not be assigned to any
+// field of the destination struct.
+type UnknownKeyError struct {
+	Keys []UnknownKey
+}
+
+func (e *UnknownKeyError) Error() string {
+	keys := make([]string, len(e.Keys))
+	for i, key := range e.Keys {
+		keys[i] = key.String()
+	}
+	return "unknown keys: " + strings.Join(keys, ", ")
+}
+
+// checkUnknownKeys checks if all keys of the given yaml node (and all arguments of the given reader) can be
+// assigned to a field of the destination struct. If not an UnknownKeyError will be returned.
+func (c *Config) checkUnknownKeys(body ast.Node, reader any, origin func(tk *token.Token) Origin) error {
+	type orderedKey struct {
+		UnknownKey
+		order int
+	}
+
+	infos := c.collectInfos()
+	var keys []orderedKey
+
+	r, isReader := reader.(*Reader)
+	if isReader {
+		suggestions := infos
+		if r.envNames == nil {
+			// the fields of the subcommands can not be set by the arguments of this command
+			suggestions = c.commandInfos()
+		}
+		for _, i := range r.skipped {
+			suggestion, info := suggestions.suggest(c.argumentPath(r.args[i]))
+			keys = append(keys, orderedKey{
+				UnknownKey: UnknownKey{
+					Key:        c.maskArgument(r.args[i], info),
+					Origin:     r.origin(i),
+					Suggestion: suggestion,
+				},
+				order: i,
+			})
+		}
+	}
+
+	walkYaml(body, nil, nil, func(segments []string, tk *token.Token) {
+		if infos.known(segments) {
+			return
+		}
+
+		line := 0
+		if tk != nil {
+			line = tk.Position.Line
+		}
+
+		suggestion, info := infos.suggest(rawPath(segments))
+		key := orderedKey{
+			UnknownKey: UnknownKey{
+				Key:        c.joinPath(segments),
+				Origin:     origin(tk),
+				Suggestion: suggestion,
+			},
+			order: line,
+		}
+		if isReader {
+			key.order = r.lineArgs[line]
+			key.Key = c.maskArgument(r.args[key.order], info)
+		}
+		keys = append(keys, key)
+	})
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	slices.SortStableFunc(keys, func(a, b orderedKey) int {
+		return cmp.Compare(a.order, b.order)
+	})
+
+	err := &UnknownKeyError{}
+	for _, key := range keys {
+		err.Keys = append(err.Keys, key.UnknownKey)
+	}
+	return err
+}
+
+// argumentPath extracts the raw path segments of the given argument (e.g. "--map[key].value=v" -> [map key value]).
+func (c *Config) argumentPath(arg string) []string {
+	key := strings.TrimPrefix(arg, c.options.prefixLong)
+	key = strings.TrimPrefix(key, c.options.prefixShort)
+	key, _, _ = cutArgument(key, c.options.assignSign)
+
+	segments := c.splitKey(key)
+	for i := range segments {
+		segments[i] = unescapeKey(segments[i], c.options.assignSign)
+	}
+	return rawPath(segments)
+}
+
+// suggest returns the flag of the field which is most similar to the given raw path segments (and the field itself).
+func (f *fieldInfos) suggest(raw []string) (string, *fieldInfo) {
+	unknown := strings.Join(raw, string(f.options.keyDelimiter))
+
+	suggestion := ""
+	var suggested *fieldInfo
+	minDistance := max(1, len(unknown)/3) + 1
+	for i, info := range f.fi {
+		// use the given map keys and slice indices for the candidate
+		candidate := make([]string, 0, len(raw))
+		for _, node := range info.path {
+			candidate = append(candidate, node.key)
+			if (node.isMap || node.isSlice) && len(candidate) < len(raw) {
+				candidate = append(candidate, raw[len(candidate)])
+			}
+		}
+
+		distance := levenshtein(unknown, strings.Join(candidate, string(f.options.keyDelimiter)))
+		if distance < minDistance && distance < len(unknown) {
+			minDistance = distance
+			suggestion = info.flag(f.options)
+			suggested = &f.fi[i]
+		}
+	}
+
+	return suggestion, suggested
+}
+
+// levenshtein calculates the edit distance between the given strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,134 @@
+package yacl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type strictConfig struct {
+	Server struct {
+		Port int    `yaml:"port" short:"p"`
+		Host string `yaml:"host"`
+	} `yaml:"server"`
+	Entries []testEntry          `yaml:"entry"`
+	Map     map[string]testEntry `yaml:"map"`
+	RawMap  map[string]any       `yaml:"raw-map"`
+	Values  []string             `yaml:"value"`
+}
+
+func TestConfig_Strict_Arguments(t *testing.T) {
+	c := strictConfig{}
+	err := NewConfig(&c, WithStrict(true)).ParseArguments(
+		"--sevrer.port=80",
+		"-p", "80",
+		"--entry[0].key=key",
+		"--entry[0].vaule=value",
+		"garbage",
+		"-x",
+	)
+
+	var ukErr *UnknownKeyError
+	assert.True(t, errors.As(err, &ukErr))
+	assert.Equal(t, []UnknownKey{
+		{Key: "--sevrer.port=80", Origin: Origin{Kind: OriginArgument, Index: 0}, Suggestion: "--server.port"},
+		{Key: "--entry[0].vaule=value", Origin: Origin{Kind: OriginArgument, Index: 4}, Suggestion: "--entry[int].value"},
+		{Key: "garbage", Origin: Origin{Kind: OriginArgument, Index: 5}},
+		{Key: "-x", Origin: Origin{Kind: OriginArgument, Index: 6}},
+	}, ukErr.Keys)
+	assert.Equal(t, "unknown keys: "+
+		"--sevrer.port=80 (argument #0) did you mean --server.port?, "+
+		"--entry[0].vaule=value (argument #4) did you mean --entry[int].value?, "+
+		"garbage (argument #5), "+
+		"-x (argument #6)", err.Error())
+}
+
+func TestConfig_Strict_KnownKeys(t *testing.T) {
+	c := strictConfig{}
+	assert.NoError(t, NewConfig(&c, WithStrict(true)).ParseArguments(
+		"--server.host=localhost",
+		"-p=81",
+		"--entry[0].key=key",
+		"--entry.[1].value=value",
+		"--map[my key].key=key",
+		"--map.other.value=value",
+		"--raw-map.deep.key=value",
+		"--value=v1",
+		"--value=v2",
+	))
+	assert.Equal(t, 81, c.Server.Port)
+	assert.Equal(t, []string{"v1", "v2"}, c.Values)
+}
+
+func TestConfig_Strict_Environment(t *testing.T) {
+	c := strictConfig{}
+	err := NewConfig(&c, WithStrict(true)).ParseEnvironment(
+		"CFG_PORT=--server.port=80",
+		"CFG_HOST=--server.hots=localhost",
+	)
+
+	var ukErr *UnknownKeyError
+	assert.True(t, errors.As(err, &ukErr))
+	assert.Equal(t, []UnknownKey{
+		{Key: "--server.hots=localhost", Origin: Origin{Kind: OriginEnvironment, Name: "CFG_HOST"}, Suggestion: "--server.host"},
+	}, ukErr.Keys)
+}
+
+func TestConfig_Strict_Yaml(t *testing.T) {
+	c := strictConfig{}
+	err := NewConfig(&c, WithStrict(true)).ParseYaml(strings.NewReader(`
+server:
+  port: 80
+  hots: localhost
+map:
+  key:
+    value: value
+`))
+
+	var ukErr *UnknownKeyError
+	assert.True(t, errors.As(err, &ukErr))
+	assert.Equal(t, []UnknownKey{
+		{Key: "server.hots", Origin: Origin{Kind: OriginFile, Line: 4}, Suggestion: "--server.host"},
+	}, ukErr.Keys)
+}
+
+func TestConfig_Strict_Load(t *testing.T) {
+	c := strictConfig{}
+	err := NewConfig(&c, WithStrict(true)).Load(
+		FileSource(writeFile(t, "config.yaml", "server:\n  prot: 80")),
+		ArgumentSource("--server.host=localhost"),
+	)
+
+	var ukErr *UnknownKeyError
+	assert.True(t, errors.As(err, &ukErr))
+	assert.Equal(t, "--server.port", ukErr.Keys[0].Suggestion)
+	assert.Equal(t, "localhost", c.Server.Host)
+}
+
+func TestConfig_NotStrict(t *testing.T) {
+	c := strictConfig{}
+	assert.NoError(t, NewConfig(&c).ParseArguments("--sevrer.port=80", "garbage"))
+	assert.NoError(t, NewConfig(&c).ParseYaml(strings.NewReader("sevrer: 80")))
+}
+
+func Test_levenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"server.port", "server.port", 0},
+		{"sevrer.port", "server.port", 2},
+		{"kitten", "sitting", 3},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%d", t.Name(), i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, levenshtein(tt.a, tt.b))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -55,6 +56,11 @@ func (f *fieldInfos) HelpFlags() string {
 		shortIntend = strings.Repeat(" ", maxShortLen)
 	}
 
+	if synopsis := f.synopsis(); synopsis != "" {
+		sb.WriteString(synopsis)
+		sb.WriteString("\n\n")
+	}
+
 	for _, info := range f.fi {
 		short := info.short
 		if short != "" {
@@ -64,24 +70,15 @@ func (f *fieldInfos) HelpFlags() string {
 			short = shortIntend
 		}
 
-		long := f.options.prefixLong + info.path.key(f.options, "int")
-		if strings.HasPrefix(info.sType, "[]") {
-			// we can dismiss the slice key in case there is a slice of primitives
-			long = strings.TrimSuffix(long, ".[int]")
-		}
-		long = strings.ReplaceAll(long, ".[", "[")
+		long := info.flag(f.options)
 		long += string(f.options.assignSign)
+		long += info.helpType()
 
-		if strings.HasPrefix(info.sType, "map[") {
-			// only show the value-type of the map
-			valueType := info.Field().Type.Elem().Kind().String()
-			if valueType == "interface" {
-				valueType = "any"
-			}
-
-			long += valueType
-		} else {
-			long += strings.TrimPrefix(info.sType, "*") // remove pointer prefix
+		if info.required {
+			long += " (required)"
+		}
+		if f.options.envMode == EnvModeNamed {
+			long += " [$" + info.envName(f.options) + "]"
 		}
 
 		sb.WriteString(intend)
@@ -102,14 +99,110 @@ func (f *fieldInfos) HelpFlags() string {
 			sb.WriteString(intend)
 			sb.WriteString(shortIntend)
 			sb.WriteString("\t")
-			sb.WriteString(fmt.Sprintf("Default: %v", info.defaultValue))
+			sb.WriteString("Default: " + info.helpDefault())
 		}
 		sb.WriteString("\n")
 	}
 
+	if len(f.commands) > 0 {
+		sb.WriteString("\nCommands:\n")
+		sb.WriteString(f.helpCommands(intend))
+	}
+
+	if f.globals != nil && len(f.globals.fi) > 0 {
+		sb.WriteString("\nGlobal options:\n")
+		sb.WriteString(f.globals.HelpFlags())
+	}
+
 	return sb.String()
 }
 
+// helpCommands returns the list of the subcommands with their usage.
+func (f *fieldInfos) helpCommands(intend string) string {
+	var sb strings.Builder
+
+	maxNameLen := 0
+	for _, cmd := range f.commands {
+		maxNameLen = max(maxNameLen, len(cmd.name))
+	}
+
+	for _, cmd := range f.commands {
+		sb.WriteString(intend)
+		if cmd.usage == "" {
+			sb.WriteString(cmd.name)
+		} else {
+			sb.WriteString(fmt.Sprintf("%-*s%s%s", maxNameLen, cmd.name, intend, cmd.usage))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// synopsis returns the usage synopsis line which contains the positional arguments (if there are any).
+// For example: "Usage: [options] <source> [<target>...]"
+func (f *fieldInfos) synopsis() string {
+	var positionals []string
+	var rest string
+	for i := 0; ; i++ {
+		info := f.findByPositional(strconv.Itoa(i))
+		if info == nil {
+			break
+		}
+		positionals = append(positionals, "<"+info.path[len(info.path)-1].key+">")
+	}
+	if info := f.findByPositional(positionalRest); info != nil {
+		rest = "[<" + info.path[len(info.path)-1].key + ">...]"
+	}
+
+	if len(positionals) == 0 && rest == "" {
+		return ""
+	}
+
+	parts := append([]string{"Usage:", "[options]"}, positionals...)
+	if rest != "" {
+		parts = append(parts, rest)
+	}
+	return strings.Join(parts, " ")
+}
+
+// helpType returns the type of the field as it is shown in the help (e.g. "int", "[]string").
+func (f *fieldInfo) helpType() string {
+	if f.typeHint != "" {
+		return f.typeHint
+	}
+	if strings.HasPrefix(f.sType, "map[") {
+		// only show the value-type of the map
+		valueType := typeName(f.Field().Type.Elem())
+		if valueType == "interface" {
+			valueType = "any"
+		}
+		return valueType
+	}
+	return strings.TrimPrefix(f.sType, "*") // remove pointer prefix
+}
+
+// helpDefault returns the default value as it is shown in the help. Secret values are masked.
+func (f *fieldInfo) helpDefault() string {
+	if f.Secret() {
+		return SecretMask
+	}
+	if st, ok := lookupScalarType(reflect.TypeOf(f.defaultValue)); ok {
+		return formatScalar(st, reflect.ValueOf(f.defaultValue))
+	}
+	return fmt.Sprintf("%v", f.defaultValue)
+}
+
+// flag returns the (long) flag of the field, e.g. "--map[string].value" or "--array[int].key".
+func (f *fieldInfo) flag(options Options) string {
+	long := options.prefixLong + f.path.key(options, "int")
+	if strings.HasPrefix(f.sType, "[]") {
+		// we can dismiss the slice key in case there is a slice of primitives
+		long = strings.TrimSuffix(long, string(options.keyDelimiter)+"[int]")
+	}
+	return strings.ReplaceAll(long, string(options.keyDelimiter)+"[", "[")
+}
+
 func (f *fieldInfos) HelpYaml() string {
 	fakeArgs := make([]string, 0, len(f.fi))
 
@@ -117,20 +210,12 @@ func (f *fieldInfos) HelpYaml() string {
 		arg := f.options.prefixLong
 		arg += fInfo.path.key(f.options, "0")
 		arg += string(f.options.assignSign)
-
-		if strings.HasPrefix(fInfo.sType, "map[") {
-			// only show the value-type of the map
-			valueType := fInfo.Field().Type.Elem().Kind().String()
-			if valueType == "interface" {
-				valueType = "any"
-			}
-
-			arg += valueType
-		} else {
-			arg += strings.TrimPrefix(fInfo.sType, "*") // remove pointer prefix
-		}
+		arg += fInfo.helpType()
 
 		help := fInfo.path.Usage()
+		if fInfo.required {
+			help = strings.TrimSpace(help + " (required)")
+		}
 		if help != "" {
 			arg += " # " + help
 		}
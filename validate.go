@@ -0,0 +1,282 @@
+package yacl
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator can be implemented by the destination struct (and all nested structs, slice elements and map values)
+// to validate its values. It is called after the values are parsed and the default values are applied.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes an invalid value of the destination struct.
+type FieldError struct {
+	// Flag is the flag of the invalid value (e.g. "--server.port"). It is empty for the destination struct itself.
+	Flag string
+
+	Err error
+}
+
+func (f FieldError) Error() string {
+	if f.Flag == "" {
+		return f.Err.Error()
+	}
+	return fmt.Sprintf("%s %s", f.Flag, f.Err)
+}
+
+func (f FieldError) Unwrap() error {
+	return f.Err
+}
+
+// ValidationError is returned if there are invalid values in the destination struct (see Config.Validate).
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = field.Error()
+	}
+	return "invalid values: " + strings.Join(fields, ", ")
+}
+
+// Validate validates the values of the destination struct (and of the selected subcommands). The values are
+// validated by the validation tag (see WithValidateTag) and by all implementations of Validator.
+// The supported rules of the tag are:
+//   - required: the value must not be empty (zero value, nil or empty slice/map)
+//   - min=N: numbers must be at least N, strings, slices and maps must have at least N elements
+//   - max=N: numbers must be at most N, strings, slices and maps must have at most N elements
+//   - oneof=a|b|c: the value must be one of the given values
+//
+// Empty values which were not set by any source (or by a default value) are only checked against the required rule.
+// All invalid values are collected and returned as ValidationError.
+func (c *Config) Validate() error {
+	return c.validate(false)
+}
+
+// validateSet validates the values like Validate, but the validation tags of values which were not set are ignored
+// completely (including the required rule). It is used after a single file is parsed, because the other values
+// can be given by the following sources (like the arguments).
+func (c *Config) validateSet() error {
+	return c.validate(true)
+}
+
+func (c *Config) validate(onlySet bool) error {
+	var fields []FieldError
+	c.validateRecursive(reflect.ValueOf(c.dest), nil, onlySet, &fields)
+	for _, cmd := range c.chain[min(1, len(c.chain)):] {
+		cmd.validateRecursive(reflect.ValueOf(cmd.dest), nil, onlySet, &fields)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
+
+func (c *Config) validateRecursive(v reflect.Value, path []string, onlySet bool, fields *[]FieldError) {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if !v.IsNil() {
+			c.validateRecursive(v.Elem(), path, onlySet, fields)
+		}
+		return
+	}
+
+	target := v.Interface()
+	if v.CanAddr() {
+		target = v.Addr().Interface()
+	}
+	if validator, ok := target.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			*fields = append(*fields, FieldError{Flag: c.flagOf(path), Err: err})
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			yamlTag := field.Tag.Get("yaml")
+			if yamlTag == "" || yamlTag == "-" {
+				continue
+			}
+			if field.Tag.Get(c.options.commandTag) != "" {
+				// subcommands are only validated if they are selected
+				continue
+			}
+
+			subPath := path
+			if key := strings.Split(yamlTag, ",")[0]; key != "" {
+				subPath = append(slices.Clip(path), key)
+			}
+
+			if rules := field.Tag.Get(c.options.validateTag); rules != "" {
+				set := c.isSet(subPath, v.Field(i))
+				if set || !onlySet {
+					for _, err := range validateRules(v.Field(i), rules, set) {
+						*fields = append(*fields, FieldError{Flag: c.flagOf(subPath), Err: err})
+					}
+				}
+			}
+			c.validateRecursive(v.Field(i), subPath, onlySet, fields)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.validateRecursive(v.Index(i), append(slices.Clip(path), fmt.Sprintf("[%d]", i)), onlySet, fields)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, key := range keys {
+			// map values are not addressable
+			temp := reflect.New(v.Type().Elem()).Elem()
+			temp.Set(v.MapIndex(key))
+			c.validateRecursive(temp, append(slices.Clip(path), fmt.Sprintf("[%v]", key.Interface())), onlySet, fields)
+		}
+	default:
+		// ignore other types
+	}
+}
+
+// flagOf returns the flag of the given normalized path (e.g. [array [0] key] -> "--array[0].key").
+func (c *Config) flagOf(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return c.options.prefixLong + strings.ReplaceAll(c.joinPath(path), string(c.options.keyDelimiter)+"[", "[")
+}
+
+// isSet checks if the value behind the given path is set: it is not empty or it was set by any source (or by a
+// default value).
+func (c *Config) isSet(path []string, v reflect.Value) bool {
+	if !isEmpty(v) {
+		return true
+	}
+
+	key := c.joinPath(path)
+	for origin := range c.origins {
+		if origin == key || strings.HasPrefix(origin, key+string(c.options.keyDelimiter)) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRules validates the given value against the given (comma separated) rules. If the value is not set,
+// only the required rule is checked.
+func validateRules(v reflect.Value, rules string, set bool) []error {
+	var errs []error
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		if name == "required" {
+			if isEmpty(v) {
+				errs = append(errs, errors.New("is required"))
+			}
+			continue
+		}
+		if !set {
+			continue
+		}
+
+		value := v
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				// optional values are only validated if they are set
+				continue
+			}
+			value = value.Elem()
+		}
+		value = unwrapSecret(value)
+
+		var err error
+		switch name {
+		case "min":
+			err = validateBound(value, name, arg, func(actual, limit float64) bool { return actual >= limit }, "at least")
+		case "max":
+			err = validateBound(value, name, arg, func(actual, limit float64) bool { return actual <= limit }, "at most")
+		case "oneof":
+			if !slices.Contains(strings.Split(arg, "|"), fmt.Sprint(value.Interface())) {
+				err = fmt.Errorf("must be one of %s", arg)
+			}
+		case "":
+		default:
+			err = fmt.Errorf("has unknown validation rule %q", name)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// validateBound checks the value (or the length) of the given value against the given limit.
+func validateBound(v reflect.Value, rule, arg string, check func(actual, limit float64) bool, relation string) error {
+	var actual, limit float64
+	var err error
+	lengthBased := false
+
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		lengthBased = true
+		actual = float64(v.Len())
+		limit, err = strconv.ParseFloat(arg, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(v.Int())
+		if v.Type() == durationType {
+			var d time.Duration
+			d, err = time.ParseDuration(arg)
+			limit = float64(d)
+		} else {
+			limit, err = strconv.ParseFloat(arg, 64)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(v.Uint())
+		limit, err = strconv.ParseFloat(arg, 64)
+	case reflect.Float32, reflect.Float64:
+		actual = v.Float()
+		limit, err = strconv.ParseFloat(arg, 64)
+	default:
+		return fmt.Errorf("does not support the validation rule %q", rule)
+	}
+
+	if err != nil {
+		return fmt.Errorf("has invalid validation limit %q", arg)
+	}
+	if check(actual, limit) {
+		return nil
+	}
+	if lengthBased {
+		return fmt.Errorf("must have a length of %s %s", relation, arg)
+	}
+	return fmt.Errorf("must be %s %s", relation, arg)
+}
@@ -0,0 +1,185 @@
+package yacl
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+type validateEntry struct {
+	Key   string `yaml:"key" validate:"required"`
+	Value string `yaml:"value"`
+}
+
+func (v validateEntry) Validate() error {
+	if v.Value == "invalid" {
+		return errors.New("has an invalid value")
+	}
+	return nil
+}
+
+type validateServer struct {
+	Port    int           `yaml:"port" validate:"min=1,max=65535"`
+	Timeout time.Duration `yaml:"timeout" validate:"max=1m"`
+}
+
+func (v *validateServer) Validate() error {
+	if v.Port == 22 {
+		return errors.New("must not use the ssh port")
+	}
+	return nil
+}
+
+type validateConfig struct {
+	Server   validateServer           `yaml:"server"`
+	Level    string                   `yaml:"level" validate:"oneof=debug|info|warn"`
+	Name     *string                  `yaml:"name" validate:"min=3"`
+	Tags     []string                 `yaml:"tags" validate:"required,max=2"`
+	Entries  []validateEntry          `yaml:"entry"`
+	EntryMap map[string]validateEntry `yaml:"map"`
+}
+
+func (v *validateConfig) SetDefaults() {
+	if v.Level == "" {
+		v.Level = "info"
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := validateConfig{}
+	err := NewConfig(&c).ParseArguments(
+		"--server.port=70000",
+		"--server.timeout=2m",
+		"--level=trace",
+		"--name=ab",
+		"--entry[0].value=invalid",
+		"--map[test].key=key",
+		"--map[test].value=invalid",
+	)
+
+	var vErr *ValidationError
+	assert.True(t, errors.As(err, &vErr))
+	assert.Equal(t, []string{
+		"--server.port must be at most 65535",
+		"--server.timeout must be at most 1m",
+		"--level must be one of debug|info|warn",
+		"--name must have a length of at least 3",
+		"--tags is required",
+		"--entry[0] has an invalid value",
+		"--entry[0].key is required",
+		"--map[test] has an invalid value",
+	}, fieldErrors(vErr))
+}
+
+func TestConfig_Validate_Valid(t *testing.T) {
+	c := validateConfig{}
+	assert.NoError(t, NewConfig(&c).ParseArguments(
+		"--server.port=80",
+		"--tags=a",
+		"--tags=b",
+		"--entry[0].key=key",
+	))
+
+	// the default value is valid
+	assert.Equal(t, "info", c.Level)
+}
+
+func TestConfig_Validate_Validator(t *testing.T) {
+	c := validateConfig{}
+	err := NewConfig(&c).ParseYaml(strings.NewReader(`
+server:
+  port: 22
+level: trace
+tags: [a]
+`))
+
+	var vErr *ValidationError
+	assert.True(t, errors.As(err, &vErr))
+	assert.Equal(t, []string{
+		"--server must not use the ssh port",
+		"--level must be one of debug|info|warn",
+	}, fieldErrors(vErr))
+	assert.Equal(t, "invalid values: --server must not use the ssh port, --level must be one of debug|info|warn", err.Error())
+}
+
+func TestConfig_Validate_Load(t *testing.T) {
+	c := validateConfig{}
+	toTest := NewConfig(&c)
+
+	// the validation is done once after all sources are applied
+	assert.NoError(t, toTest.Load(
+		FileSource(writeFile(t, "config.yaml", "server:\n  port: 0")),
+		ArgumentSource("--server.port=80", "--tags=a"),
+	))
+
+	err := toTest.Load(ArgumentSource("--server.port=0"))
+	assert.ErrorContains(t, err, "--server.port must be at least 1")
+}
+
+func TestConfig_Validate_ParseYamlAndArguments(t *testing.T) {
+	c := validateConfig{}
+	toTest := NewConfig(&c, WithAutoApplyDefaults(false))
+
+	// the required tags are given by the arguments, the level is not set at all
+	assert.NoError(t, toTest.ParseYaml(strings.NewReader("server:\n  port: 80\n")))
+	assert.NoError(t, toTest.ParseArguments("--tags=a"))
+	assert.Equal(t, "", c.Level)
+
+	// values which are set by the file are validated immediately
+	err := toTest.ParseYaml(strings.NewReader("server:\n  port: 0\ntags: []\n"))
+	assert.EqualError(t, err, "invalid values: --server.port must be at least 1, --tags is required")
+}
+
+func TestConfig_Validate_Disabled(t *testing.T) {
+	c := validateConfig{}
+	toTest := NewConfig(&c, WithAutoValidate(false))
+
+	assert.NoError(t, toTest.ParseArguments("--level=trace"))
+	assert.Error(t, toTest.Validate())
+}
+
+func TestConfig_Validate_Command(t *testing.T) {
+	c := struct {
+		Serve *validateServer `yaml:"serve" command:"serve"`
+	}{}
+	toTest := NewConfig(&c)
+
+	// not selected commands are not validated
+	assert.NoError(t, toTest.ParseArguments())
+
+	err := toTest.ParseArguments("serve", "--port=0")
+	assert.EqualError(t, err, "invalid values: --port must be at least 1")
+}
+
+func fieldErrors(err *ValidationError) []string {
+	result := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		result[i] = field.Error()
+	}
+	return result
+}
+
+func TestConfig_Validate_WithMissingRequired(t *testing.T) {
+	c := struct {
+		Server validateServer `yaml:"server"`
+		URL    string         `yaml:"url" required:"true"`
+	}{}
+	args := []string{"--server.port=70000"}
+
+	for _, err := range []error{
+		NewConfig(&c).ParseArguments(args...),
+		NewConfig(&c).Load(ArgumentSource(args...)),
+	} {
+		var mrErr *MissingRequiredError
+		assert.True(t, errors.As(err, &mrErr))
+		assert.Equal(t, []string{"--url"}, mrErr.Paths)
+
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, []string{"--server.port must be at most 65535"}, fieldErrors(vErr))
+
+		assert.EqualError(t, err, "missing required options: --url\ninvalid values: --server.port must be at most 65535")
+	}
+}
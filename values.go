@@ -0,0 +1,71 @@
+package yacl
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// walkValues walks through all (leaf) values of the given value and calls fn with the normalized path of each value.
+// The structs are walked the same way as they are inspected (see Config.scan): only fields with a yaml tag are
+// considered. Nil pointers, nil interfaces and empty maps/slices are skipped.
+func (c *Config) walkValues(v reflect.Value, path []string, fn func(path []string, v reflect.Value)) {
+	if v.IsValid() && v.Kind() != reflect.Ptr && isScalarType(v.Type()) {
+		// well-known types (like time.Duration or net.IP) are represented by a single value
+		if len(path) > 0 && !(v.Kind() == reflect.Slice && v.IsNil()) {
+			fn(path, v)
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		c.walkValues(v.Elem(), path, fn)
+	case reflect.Struct:
+		if isSecretType(v.Type()) {
+			// secrets are wrapping a single value
+			fn(path, v)
+			return
+		}
+
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			yamlTag := field.Tag.Get("yaml")
+			if yamlTag == "" || yamlTag == "-" {
+				continue
+			}
+
+			subPath := path
+			if key := strings.Split(yamlTag, ",")[0]; key != "" {
+				subPath = append(slices.Clip(path), key)
+			}
+			c.walkValues(v.Field(i), subPath, fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.walkValues(v.Index(i), append(slices.Clip(path), fmt.Sprintf("[%d]", i)), fn)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, key := range keys {
+			c.walkValues(v.MapIndex(key), append(slices.Clip(path), fmt.Sprintf("[%v]", key.Interface())), fn)
+		}
+	default:
+		if len(path) > 0 {
+			fn(path, v)
+		}
+	}
+}
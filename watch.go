@@ -0,0 +1,155 @@
+package yacl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Watcher holds the current value of a watched configuration (see Watch). It is safe for concurrent use.
+type Watcher[T any] struct {
+	current atomic.Pointer[T]
+
+	mu  sync.Mutex
+	err error
+}
+
+// Get returns the current value. The returned value must not be modified, because it could be read concurrently.
+func (w *Watcher[T]) Get() *T {
+	return w.current.Load()
+}
+
+// Err returns the error of the last reload (or nil if the last reload was successful).
+func (w *Watcher[T]) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+func (w *Watcher[T]) setErr(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.err = err
+}
+
+// Watch watches the given file and reloads the configuration if the content of the file changes. The file is
+// checked in the interval given by WithWatchInterval. The configuration must be loaded by Config.Load (and must not
+// be parsed by other functions like ParseArguments), because only the sources of Load can be applied again.
+// Each reload runs the whole pipeline of Config.Load (the sources of the last Load call, defaults, references,
+// required fields and validation) into a fresh value of the destination type. If the watched file is not one of
+// these sources, it is added as FileSource. The subcommands which are registered by NewCommand are reloaded too, but
+// only the destination is published. The initial value of the watcher is loaded the same way, so it is independent
+// of the destination. Only if a reload is successful, the new value is swapped in (see Watcher.Get) and onChange is
+// called with the old value, the new value and the changed fields. Otherwise, the old value stays and the error is
+// available via Watcher.Err.
+// The watching stops when the given context is done.
+func Watch[T any](ctx context.Context, c *Config, path string, onChange func(old, new T, diff []Change)) (*Watcher[T], error) {
+	dest, ok := c.dest.(*T)
+	if !ok {
+		return nil, fmt.Errorf("destination is of type %T instead of %T", c.dest, dest)
+	}
+	if !c.loaded || c.parsed {
+		return nil, errors.New("only configurations which are loaded by Config.Load can be watched")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher[T]{}
+	initial, err := w.load(c, path)
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(initial)
+
+	go func() {
+		ticker := time.NewTicker(c.options.watchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			changed, err := os.ReadFile(path)
+			if err != nil {
+				w.setErr(err)
+				continue
+			}
+			if bytes.Equal(content, changed) {
+				continue
+			}
+			content = changed
+
+			w.reload(c, path, onChange)
+		}
+	}()
+
+	return w, nil
+}
+
+func (w *Watcher[T]) reload(c *Config, path string, onChange func(old, new T, diff []Change)) {
+	next, err := w.load(c, path)
+	if err != nil {
+		w.setErr(err)
+		return
+	}
+	w.setErr(nil)
+
+	old := w.current.Load()
+	diff := c.changes(old, next)
+	if len(diff) == 0 {
+		return
+	}
+
+	w.current.Store(next)
+	if onChange != nil {
+		onChange(*old, *next, diff)
+	}
+}
+
+// load applies the sources of the last Load of the given config (and the watched file) to a fresh value.
+func (w *Watcher[T]) load(c *Config, path string) (*T, error) {
+	sources := slices.Clone(c.sources)
+	if !slices.ContainsFunc(sources, func(s Source) bool {
+		return s.Precedence() == PrecedenceFile && s.Name() == path
+	}) {
+		sources = append(sources, FileSource(path))
+	}
+
+	next := new(T)
+	if err := c.fresh(next, nil).Load(sources...); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// fresh returns a config with the same options and subcommands (see NewCommand) as this one, but with the given
+// destination. The destinations of the subcommands are fresh values of their types.
+func (c *Config) fresh(dest any, parent *Config) *Config {
+	nc := &Config{
+		options:  c.options,
+		dest:     dest,
+		name:     c.name,
+		usage:    c.usage,
+		parent:   parent,
+		profiles: slices.Clone(c.options.activeProfiles),
+	}
+	for _, cmd := range c.commands {
+		nc.commands = append(nc.commands, cmd.fresh(reflect.New(reflect.TypeOf(cmd.dest).Elem()).Interface(), nc))
+	}
+	return nc
+}
@@ -0,0 +1,120 @@
+package yacl
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+type watchConfig struct {
+	Port     int    `yaml:"port" validate:"max=65535"`
+	Host     string `yaml:"host"`
+	Password string `yaml:"password" secret:"true"`
+}
+
+// replaceFile replaces the content of the given file atomically (the watcher must not see partial content).
+func replaceFile(t *testing.T, path, content string) {
+	temp := path + ".tmp"
+	assert.NoError(t, os.WriteFile(temp, []byte(content), 0644))
+	assert.NoError(t, os.Rename(temp, path))
+}
+
+func TestWatch(t *testing.T) {
+	path := writeFile(t, "config.yaml", "port: 80\nhost: localhost\npassword: old")
+
+	c := watchConfig{}
+	config := NewConfig(&c, WithWatchInterval(5*time.Millisecond))
+	assert.NoError(t, config.Load(FileSource(path), ArgumentSource("--host=example.com")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type event struct {
+		old, new watchConfig
+		diff     []Change
+	}
+	events := make(chan event, 10)
+
+	watcher, err := Watch(ctx, config, path, func(old, new watchConfig, diff []Change) {
+		events <- event{old, new, diff}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &c, watcher.Get())
+
+	// the current value is a copy of the destination
+	assert.NotSame(t, &c, watcher.Get())
+	c.Host = "modified"
+	assert.Equal(t, "example.com", watcher.Get().Host)
+
+	// invalid values are not swapped in
+	replaceFile(t, path, "port: 70000\nhost: localhost\npassword: old")
+	assert.Eventually(t, func() bool { return watcher.Err() != nil }, time.Second, time.Millisecond)
+	assert.Equal(t, 80, watcher.Get().Port)
+
+	replaceFile(t, path, "port: 8080\nhost: localhost\npassword: new")
+
+	select {
+	case e := <-events:
+		assert.Equal(t, 80, e.old.Port)
+		assert.Equal(t, 8080, e.new.Port)
+		assert.Equal(t, "example.com", e.old.Host)
+		assert.Equal(t, "example.com", e.new.Host)
+		assert.Equal(t, []Change{
+			{Path: "--port", Old: 80, New: 8080},
+			{Path: "--password", Old: SecretMask, New: SecretMask},
+		}, e.diff)
+	case <-time.After(time.Second):
+		assert.Fail(t, "no change was reported")
+	}
+	assert.NoError(t, watcher.Err())
+	assert.Equal(t, 8080, watcher.Get().Port)
+	assert.Equal(t, 80, c.Port, "the original destination must not be modified")
+}
+
+func TestWatch_NotLoaded(t *testing.T) {
+	path := writeFile(t, "config.yaml", "port: 80\n")
+
+	c := watchConfig{}
+	_, err := Watch(context.Background(), NewConfig(&c), path, func(old, new watchConfig, diff []Change) {})
+	assert.EqualError(t, err, "only configurations which are loaded by Config.Load can be watched")
+
+	// the arguments would get lost by a reload
+	config := NewConfig(&c)
+	assert.NoError(t, config.Load(FileSource(path)))
+	assert.NoError(t, config.ParseArguments("--host=example.com"))
+	_, err = Watch(context.Background(), config, path, func(old, new watchConfig, diff []Change) {})
+	assert.EqualError(t, err, "only configurations which are loaded by Config.Load can be watched")
+}
+
+func TestWatch_Command(t *testing.T) {
+	path := writeFile(t, "config.yaml", "port: 80\n")
+
+	c := watchConfig{}
+	serve := struct {
+		Workers int `yaml:"workers" validate:"required"`
+	}{}
+	config := NewConfig(&c, WithStrict(true), WithWatchInterval(5*time.Millisecond))
+	NewCommand(config, "serve", "Start the server", &serve)
+	assert.NoError(t, config.Load(FileSource(path), ArgumentSource("serve", "--workers=2", "--host=example.com")))
+	assert.Equal(t, 2, serve.Workers)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the subcommand is part of the reload, otherwise its flags would be unknown
+	watcher, err := Watch(ctx, config, path, func(old, new watchConfig, diff []Change) {})
+	assert.NoError(t, err)
+
+	replaceFile(t, path, "port: 8080\n")
+	assert.Eventually(t, func() bool { return watcher.Get().Port == 8080 }, time.Second, time.Millisecond)
+	assert.NoError(t, watcher.Err())
+	assert.Equal(t, "example.com", watcher.Get().Host)
+}
+
+func TestWatch_WrongType(t *testing.T) {
+	c := watchConfig{}
+	_, err := Watch(context.Background(), NewConfig(&c), "config.yaml", func(old, new testConfig, diff []Change) {})
+	assert.Error(t, err)
+}